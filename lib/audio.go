@@ -0,0 +1,287 @@
+package genai_sdk
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// LiveInputFormat is the audio format Live models expect as input.
+var LiveInputFormat = PCMFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16}
+
+// DefaultAudioChunk is the chunk length used by StreamAudio.
+const DefaultAudioChunk = 100 * time.Millisecond
+
+// PCMMIMEType returns the raw PCM MIME type for rate, e.g. "audio/pcm;rate=16000".
+func PCMMIMEType(rate int) string {
+	return fmt.Sprintf("audio/pcm;rate=%d", rate)
+}
+
+// Duration returns the playback length of n bytes of PCM in format f.
+func (f PCMFormat) Duration(n int) time.Duration {
+	if f.SampleRate <= 0 || f.blockAlign() <= 0 {
+		return 0
+	}
+	frames := n / f.blockAlign()
+	return time.Duration(frames) * time.Second / time.Duration(f.SampleRate)
+}
+
+// ConvertPCM converts pcm from one format to another. Channels are averaged
+// down to mono or mono is duplicated to every output channel; other channel
+// layouts must match. Resampling uses a windowed-sinc filter that, when
+// downsampling, removes content above the target Nyquist frequency instead of
+// letting it alias.
+func ConvertPCM(pcm []byte, from, to PCMFormat) ([]byte, error) {
+	if err := from.validate(); err != nil {
+		return nil, fmt.Errorf("invalid source format: %w", err)
+	}
+	if err := to.validate(); err != nil {
+		return nil, fmt.Errorf("invalid target format: %w", err)
+	}
+	if from.Channels != to.Channels && from.Channels != 1 && to.Channels != 1 {
+		return nil, fmt.Errorf("cannot convert %d channels to %d", from.Channels, to.Channels)
+	}
+	if from == to {
+		return pcm[:len(pcm)-len(pcm)%from.blockAlign()], nil
+	}
+
+	samples := decodePCM(pcm, from)
+	samples = remixSamples(samples, from.Channels, to.Channels)
+	samples = resampleSamples(samples, to.Channels, from.SampleRate, to.SampleRate)
+	return encodePCM(samples, to), nil
+}
+
+// ToLivePCM converts pcm to LiveInputFormat.
+func ToLivePCM(pcm []byte, from PCMFormat) ([]byte, error) {
+	return ConvertPCM(pcm, from, LiveInputFormat)
+}
+
+// ChunkPCM splits pcm into frame-aligned chunks of at most d of audio. The
+// chunks share pcm's backing array.
+func ChunkPCM(pcm []byte, format PCMFormat, d time.Duration) [][]byte {
+	if d <= 0 {
+		d = DefaultAudioChunk
+	}
+	frame := format.blockAlign()
+	if frame <= 0 || len(pcm) == 0 {
+		return nil
+	}
+	framesPerChunk := max(int(int64(format.SampleRate)*int64(d)/int64(time.Second)), 1)
+	size := framesPerChunk * frame
+
+	chunks := make([][]byte, 0, len(pcm)/size+1)
+	for start := 0; start+frame <= len(pcm); start += size {
+		end := min(start+size, len(pcm)-len(pcm)%frame)
+		chunks = append(chunks, pcm[start:end])
+	}
+	return chunks
+}
+
+// AudioStreamOptions controls StreamAudio.
+type AudioStreamOptions struct {
+	// ChunkDuration is the audio length per message; defaults to DefaultAudioChunk.
+	ChunkDuration time.Duration
+	// Unpaced sends chunks as fast as possible instead of in real time.
+	Unpaced bool
+	// EndOfStream sends audioStreamEnd after the last chunk so the server
+	// flushes its voice activity detection.
+	EndOfStream bool
+	// Clock is used for pacing; defaults to the system clock.
+	Clock Clock
+}
+
+// StreamAudio converts pcm to LiveInputFormat and sends it to the session in
+// chunks, pacing them so audio arrives no faster than it would play.
+func (s *LiveSession) StreamAudio(ctx context.Context, pcm []byte, format PCMFormat, opts AudioStreamOptions) error {
+	pcm, err := ToLivePCM(pcm, format)
+	if err != nil {
+		return err
+	}
+	clock := opts.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	mimeType := PCMMIMEType(LiveInputFormat.SampleRate)
+
+	start := clock.Now()
+	var sent time.Duration
+	for _, chunk := range ChunkPCM(pcm, LiveInputFormat, opts.ChunkDuration) {
+		if !opts.Unpaced {
+			if ahead := sent - clock.Now().Sub(start); ahead > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-clock.After(ahead):
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.SendAudioPCM(chunk, mimeType); err != nil {
+			return fmt.Errorf("failed to send audio chunk: %w", err)
+		}
+		sent += LiveInputFormat.Duration(len(chunk))
+	}
+	if opts.EndOfStream {
+		if err := s.SendRealtimeInput(genai.LiveRealtimeInput{AudioStreamEnd: true}); err != nil {
+			return fmt.Errorf("failed to send audio stream end: %w", err)
+		}
+	}
+	return nil
+}
+
+// StreamWAV reads a WAV stream and sends it with StreamAudio.
+func (s *LiveSession) StreamWAV(ctx context.Context, r io.Reader, opts AudioStreamOptions) error {
+	pcm, format, err := ReadWAV(r)
+	if err != nil {
+		return err
+	}
+	return s.StreamAudio(ctx, pcm, format, opts)
+}
+
+// decodePCM returns samples normalised to [-1, 1), interleaved by channel.
+func decodePCM(pcm []byte, f PCMFormat) []float64 {
+	width := f.BitsPerSample / 8
+	samples := make([]float64, len(pcm)/f.blockAlign()*f.Channels)
+	for i := range samples {
+		samples[i] = decodeSample(pcm[i*width:], f.BitsPerSample)
+	}
+	return samples
+}
+
+func decodeSample(b []byte, bits int) float64 {
+	switch bits {
+	case 8: // unsigned
+		return (float64(b[0]) - 128) / 128
+	case 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case 24:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+		return float64(v) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
+
+// remixSamples averages interleaved frames down to mono or duplicates mono
+// to every output channel.
+func remixSamples(samples []float64, from, to int) []float64 {
+	if from == to {
+		return samples
+	}
+	n := len(samples) / from
+	out := make([]float64, n*to)
+	for i := range n {
+		frame := samples[i*from : (i+1)*from]
+		if from == 1 {
+			for c := range to {
+				out[i*to+c] = frame[0]
+			}
+			continue
+		}
+		var sum float64
+		for _, v := range frame {
+			sum += v
+		}
+		out[i] = sum / float64(from)
+	}
+	return out
+}
+
+// resampleZeroCrossings is the number of zero crossings of the resampling
+// filter on each side of its centre, and resampleCutoff places the cutoff
+// just below the lower of the two Nyquist frequencies to leave room for the
+// filter's transition band.
+const (
+	resampleZeroCrossings = 16
+	resampleCutoff        = 0.9
+)
+
+// resampleSamples converts interleaved samples between rates with a
+// Blackman-windowed sinc low-pass filter. Each output sample is normalised by
+// the sum of its filter taps, so the edges of the input, where the filter is
+// cut short, keep their level.
+func resampleSamples(samples []float64, channels, fromRate, toRate int) []float64 {
+	if fromRate == toRate || len(samples) == 0 {
+		return samples
+	}
+	frames := len(samples) / channels
+	n := int(int64(frames) * int64(toRate) / int64(fromRate))
+	// cutoff is relative to the source Nyquist frequency.
+	cutoff := resampleCutoff * min(1, float64(toRate)/float64(fromRate))
+	half := int(math.Ceil(resampleZeroCrossings / cutoff))
+	step := float64(fromRate) / float64(toRate)
+
+	out := make([]float64, n*channels)
+	taps := make([]float64, 2*half)
+	for i := range n {
+		pos := float64(i) * step
+		first := int(pos) - half + 1
+		var total float64
+		for k := range taps {
+			j := first + k
+			if j < 0 || j >= frames {
+				taps[k] = 0
+				continue
+			}
+			t := pos - float64(j)
+			taps[k] = cutoff * sinc(cutoff*t) * blackman(t/float64(half))
+			total += taps[k]
+		}
+		for c := range channels {
+			var sum float64
+			for k, w := range taps {
+				if w != 0 {
+					sum += w * samples[(first+k)*channels+c]
+				}
+			}
+			out[i*channels+c] = sum / total
+		}
+	}
+	return out
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is the Blackman window over [-1, 1].
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
+
+func encodePCM(samples []float64, f PCMFormat) []byte {
+	width := f.BitsPerSample / 8
+	out := make([]byte, len(samples)*width)
+	for i, v := range samples {
+		encodeSample(out[i*width:], v, f.BitsPerSample)
+	}
+	return out
+}
+
+func encodeSample(b []byte, v float64, bits int) {
+	v = math.Max(-1, math.Min(v, 1))
+	switch bits {
+	case 8:
+		b[0] = uint8(math.Round(math.Min(v*128+128, 255)))
+	case 16:
+		binary.LittleEndian.PutUint16(b, uint16(int16(math.Round(math.Min(v*(1<<15), (1<<15)-1)))))
+	case 24:
+		s := int32(math.Round(math.Min(v*(1<<23), (1<<23)-1)))
+		b[0], b[1], b[2] = byte(s), byte(s>>8), byte(s>>16)
+	default:
+		binary.LittleEndian.PutUint32(b, uint32(int32(math.Round(math.Min(v*(1<<31), (1<<31)-1)))))
+	}
+}
@@ -0,0 +1,177 @@
+package genai_sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReadWAV_RoundTrip(t *testing.T) {
+	format := PCMFormat{SampleRate: 44100, Channels: 2, BitsPerSample: 16}
+	pcm := []byte{1, 0, 2, 0, 3, 0, 4, 0}
+	wav, err := EncodeWAV(pcm, format)
+	if err != nil {
+		t.Fatalf("EncodeWAV: %v", err)
+	}
+	// Insert a LIST chunk between fmt and data, as many editors do.
+	list := append([]byte("LIST\x04\x00\x00\x00INFO"), wav[36:]...)
+	wav = append(wav[:36:36], list...)
+
+	got, gotFormat, err := ReadWAV(bytes.NewReader(wav))
+	if err != nil {
+		t.Fatalf("ReadWAV: %v", err)
+	}
+	if gotFormat != format || !bytes.Equal(got, pcm) {
+		t.Errorf("ReadWAV = %v %+v, want %v %+v", got, gotFormat, pcm, format)
+	}
+
+	if _, _, err := ReadWAV(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI "))); err == nil {
+		t.Error("expected error for non-WAVE stream")
+	}
+}
+
+func TestReadWAV_BogusChunkSizes(t *testing.T) {
+	wav, err := EncodeWAV([]byte{1, 0, 2, 0}, PCMFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16})
+	if err != nil {
+		t.Fatalf("EncodeWAV: %v", err)
+	}
+
+	data := bytes.Clone(wav)
+	binary.LittleEndian.PutUint32(data[40:], 0xFFFFFFF0)
+	if _, _, err := ReadWAV(bytes.NewReader(data)); err == nil {
+		t.Error("expected error for data chunk larger than the input")
+	}
+
+	fmtChunk := bytes.Clone(wav)
+	binary.LittleEndian.PutUint32(fmtChunk[16:], 0xFFFFFFF0)
+	if _, _, err := ReadWAV(bytes.NewReader(fmtChunk)); err == nil {
+		t.Error("expected error for oversized fmt chunk")
+	}
+}
+
+func TestConvertPCM(t *testing.T) {
+	// 30ms of 48kHz stereo with a constant half-scale left channel and a
+	// silent right channel.
+	from := PCMFormat{SampleRate: 48000, Channels: 2, BitsPerSample: 16}
+	pcm := make([]byte, 1440*from.blockAlign())
+	for i := 0; i < len(pcm); i += 4 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(16384)))
+	}
+
+	out, err := ToLivePCM(pcm, from)
+	if err != nil {
+		t.Fatalf("ToLivePCM: %v", err)
+	}
+	if len(out) != 480*2 {
+		t.Fatalf("len = %d, want %d", len(out), 480*2)
+	}
+	for i := 0; i < len(out); i += 2 {
+		if v := int16(binary.LittleEndian.Uint16(out[i:])); v != 8192 {
+			t.Fatalf("sample %d = %d, want 8192", i/2, v)
+		}
+	}
+
+	if _, err := ConvertPCM(pcm, from, PCMFormat{SampleRate: 16000, Channels: 3, BitsPerSample: 16}); err == nil {
+		t.Error("expected error converting stereo to three channels")
+	}
+}
+
+func TestConvertPCM_FiltersAboveTargetNyquist(t *testing.T) {
+	// rms converts 100ms of a half-scale 48kHz sine at freq to 16kHz and
+	// returns the RMS level away from the edges.
+	rms := func(freq float64) float64 {
+		from := PCMFormat{SampleRate: 48000, Channels: 1, BitsPerSample: 16}
+		pcm := make([]byte, 4800*2)
+		for i := range 4800 {
+			v := 0.5 * math.Sin(2*math.Pi*freq*float64(i)/48000)
+			binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(v*(1<<15))))
+		}
+		out, err := ToLivePCM(pcm, from)
+		if err != nil {
+			t.Fatalf("ToLivePCM: %v", err)
+		}
+		var sum float64
+		n := 0
+		for i := 200; i < len(out)/2-200; i++ {
+			v := float64(int16(binary.LittleEndian.Uint16(out[i*2:]))) / (1 << 15)
+			sum += v * v
+			n++
+		}
+		return math.Sqrt(sum / float64(n))
+	}
+
+	if got, want := rms(1000), 0.5/math.Sqrt2; math.Abs(got-want) > 0.01 {
+		t.Errorf("1kHz RMS = %.4f, want %.4f", got, want)
+	}
+	// 12kHz cannot be represented at 16kHz; without a low-pass filter it
+	// folds back to 4kHz at full level.
+	if got := rms(12000); got > 0.005 {
+		t.Errorf("12kHz RMS = %.4f, want it filtered out", got)
+	}
+}
+
+func TestConvertPCM_BitDepths(t *testing.T) {
+	tests := []struct {
+		name string
+		bits int
+		pcm  []byte
+	}{
+		{"8-bit", 8, []byte{192}},
+		{"24-bit", 24, []byte{0, 0, 0x40}},
+		{"32-bit", 32, []byte{0, 0, 0, 0x40}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ConvertPCM(tt.pcm, PCMFormat{SampleRate: 16000, Channels: 1, BitsPerSample: tt.bits}, LiveInputFormat)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v := int16(binary.LittleEndian.Uint16(out)); v != 16384 {
+				t.Errorf("sample = %d, want 16384", v)
+			}
+		})
+	}
+}
+
+func TestChunkPCM(t *testing.T) {
+	pcm := make([]byte, 250*LiveInputFormat.SampleRate/1000*2+1) // 250ms plus a stray byte
+	chunks := ChunkPCM(pcm, LiveInputFormat, 100*time.Millisecond)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	if len(chunks[0]) != 3200 || len(chunks[2]) != 1600 {
+		t.Errorf("chunk sizes = %d, %d", len(chunks[0]), len(chunks[2]))
+	}
+	if d := LiveInputFormat.Duration(len(chunks[0])); d != 100*time.Millisecond {
+		t.Errorf("Duration = %v", d)
+	}
+}
+
+func TestLiveSession_StreamAudioPacing(t *testing.T) {
+	conn := newFakeLiveConn()
+	sess := &LiveSession{session: conn}
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	pcm := make([]byte, 16000*2*3/10) // 300ms at 16kHz
+	err := sess.StreamAudio(context.Background(), pcm, LiveInputFormat, AudioStreamOptions{
+		ChunkDuration: 100 * time.Millisecond,
+		EndOfStream:   true,
+		Clock:         clock,
+	})
+	if err != nil {
+		t.Fatalf("StreamAudio: %v", err)
+	}
+	if len(conn.realtime) != 4 {
+		t.Fatalf("sent %d messages, want 3 chunks + stream end", len(conn.realtime))
+	}
+	if conn.realtime[0].Audio.MIMEType != "audio/pcm;rate=16000" || !conn.realtime[3].AudioStreamEnd {
+		t.Errorf("unexpected messages: %+v", conn.realtime)
+	}
+	want := []time.Duration{100 * time.Millisecond, 100 * time.Millisecond}
+	if len(clock.waits) != len(want) || clock.waits[0] != want[0] || clock.waits[1] != want[1] {
+		t.Errorf("waits = %v, want %v", clock.waits, want)
+	}
+}
@@ -0,0 +1,479 @@
+package genai_sdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// batchKeyMetadata is the metadata key that carries BatchRequest.ID through
+// inlined batch requests.
+const batchKeyMetadata = "key"
+
+// BatchClient runs bulk generation and embedding work through the Gemini
+// Batch API, which is billed at a discount and is not subject to the
+// interactive rate limits.
+type BatchClient interface {
+	Submit(ctx context.Context, displayName string, requests []BatchRequest) (*genai.BatchJob, error)
+	SubmitJSONL(ctx context.Context, displayName string, r io.Reader) (*genai.BatchJob, error)
+	SubmitEmbeddings(ctx context.Context, displayName string, texts []string, config *genai.EmbedContentConfig) (*genai.BatchJob, error)
+	Get(ctx context.Context, name string) (*genai.BatchJob, error)
+	Wait(ctx context.Context, name string) (*genai.BatchJob, error)
+	Cancel(ctx context.Context, name string) error
+	Results(ctx context.Context, job *genai.BatchJob) iter.Seq2[BatchResult, error]
+	EmbeddingResults(ctx context.Context, job *genai.BatchJob) iter.Seq2[EmbeddingBatchResult, error]
+}
+
+// BatchRequest is one generate-content request in a batch job. ID is echoed
+// back on the matching BatchResult.
+type BatchRequest struct {
+	ID string
+	// Prompt is used when Contents is empty.
+	Prompt   string
+	Contents []*genai.Content
+	Config   *genai.GenerateContentConfig
+}
+
+func (r BatchRequest) contents() []*genai.Content {
+	if len(r.Contents) > 0 {
+		return r.Contents
+	}
+	return genai.Text(r.Prompt)
+}
+
+// BatchResult is the outcome of one BatchRequest. Err is set when that single
+// request failed inside an otherwise successful job.
+type BatchResult struct {
+	ID       string
+	Response *genai.GenerateContentResponse
+	Err      error
+}
+
+// EmbeddingBatchResult is the outcome of one text in an embedding batch job.
+// Index is the position of the text in the submitted slice.
+type EmbeddingBatchResult struct {
+	Index  int
+	Values []float32
+	Err    error
+}
+
+// GeminiBatchClient adapts genai.Client.Batches to the BatchClient interface.
+type GeminiBatchClient struct {
+	client         *genai.Client
+	model          string
+	embeddingModel string
+	pollInterval   time.Duration
+	maxPollDelay   time.Duration
+	logger         *slog.Logger
+}
+
+// NewGeminiBatchClient creates a BatchClient for model. Embedding jobs use
+// EmbeddingModel unless overridden with WithEmbeddingModel.
+func NewGeminiBatchClient(ctx context.Context, apiKey, model string, logger *slog.Logger) (*GeminiBatchClient, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if model == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	return &GeminiBatchClient{
+		client:         client,
+		model:          model,
+		embeddingModel: EmbeddingModel,
+		pollInterval:   10 * time.Second,
+		maxPollDelay:   2 * time.Minute,
+		logger:         logger,
+	}, nil
+}
+
+// WithEmbeddingModel overrides the model used by SubmitEmbeddings.
+func (b *GeminiBatchClient) WithEmbeddingModel(model string) *GeminiBatchClient {
+	if model != "" {
+		b.embeddingModel = model
+	}
+	return b
+}
+
+// WithPollInterval sets the initial and maximum delay between job state polls
+// in Wait. The delay doubles after each poll up to max.
+func (b *GeminiBatchClient) WithPollInterval(initial, max time.Duration) *GeminiBatchClient {
+	if initial > 0 {
+		b.pollInterval = initial
+	}
+	if max >= b.pollInterval {
+		b.maxPollDelay = max
+	}
+	return b
+}
+
+// Submit creates a batch job from inline requests. Inline jobs are limited in
+// total size by the API; use SubmitJSONL for large workloads.
+func (b *GeminiBatchClient) Submit(ctx context.Context, displayName string, requests []BatchRequest) (*genai.BatchJob, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no requests provided for batch job")
+	}
+	inlined := make([]*genai.InlinedRequest, len(requests))
+	for i, req := range requests {
+		if req.ID == "" {
+			return nil, fmt.Errorf("request at index %d has no ID", i)
+		}
+		inlined[i] = &genai.InlinedRequest{
+			Contents: req.contents(),
+			Config:   req.Config,
+			Metadata: map[string]string{batchKeyMetadata: req.ID},
+		}
+	}
+	job, err := b.client.Batches.Create(ctx, b.model,
+		&genai.BatchJobSource{InlinedRequests: inlined},
+		&genai.CreateBatchJobConfig{DisplayName: displayName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch job: %w", ClassifyError(err))
+	}
+	b.logger.InfoContext(ctx, "Batch job created",
+		slog.String("job", job.Name),
+		slog.Int("requests", len(requests)),
+		slog.String("model", b.model))
+	return job, nil
+}
+
+// SubmitJSONL uploads r as the job's input file and creates a batch job from
+// it. Each line must be {"key": ..., "request": GenerateContentRequest}, as
+// written by WriteBatchJSONL.
+func (b *GeminiBatchClient) SubmitJSONL(ctx context.Context, displayName string, r io.Reader) (*genai.BatchJob, error) {
+	file, err := b.client.Files.Upload(ctx, r, &genai.UploadFileConfig{
+		DisplayName: displayName,
+		MIMEType:    "jsonl",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload batch input: %w", ClassifyError(err))
+	}
+	job, err := b.client.Batches.Create(ctx, b.model,
+		&genai.BatchJobSource{FileName: file.Name},
+		&genai.CreateBatchJobConfig{DisplayName: displayName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch job: %w", ClassifyError(err))
+	}
+	b.logger.InfoContext(ctx, "Batch job created from file",
+		slog.String("job", job.Name),
+		slog.String("file", file.Name),
+		slog.String("model", b.model))
+	return job, nil
+}
+
+// SubmitEmbeddings creates an embedding batch job for texts. The API does not
+// echo per-item keys for embedding jobs, so results are reported by index.
+func (b *GeminiBatchClient) SubmitEmbeddings(ctx context.Context, displayName string, texts []string, config *genai.EmbedContentConfig) (*genai.BatchJob, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts provided for batch embedding")
+	}
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+	job, err := b.client.Batches.CreateEmbeddings(ctx, &b.embeddingModel,
+		&genai.EmbeddingsBatchJobSource{
+			InlinedRequests: &genai.EmbedContentBatch{Contents: contents, Config: config},
+		},
+		&genai.CreateEmbeddingsBatchJobConfig{DisplayName: displayName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding batch job: %w", ClassifyError(err))
+	}
+	b.logger.InfoContext(ctx, "Embedding batch job created",
+		slog.String("job", job.Name),
+		slog.Int("texts", len(texts)),
+		slog.String("model", b.embeddingModel))
+	return job, nil
+}
+
+// Get returns the current state of a batch job.
+func (b *GeminiBatchClient) Get(ctx context.Context, name string) (*genai.BatchJob, error) {
+	job, err := b.client.Batches.Get(ctx, name, nil)
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
+	return job, nil
+}
+
+// Cancel requests cancellation of a running batch job.
+func (b *GeminiBatchClient) Cancel(ctx context.Context, name string) error {
+	return ClassifyError(b.client.Batches.Cancel(ctx, name, nil))
+}
+
+// Wait polls the job with exponential backoff until it reaches a terminal
+// state. Jobs that end FAILED, CANCELLED or EXPIRED are returned together with
+// a *BatchJobError.
+func (b *GeminiBatchClient) Wait(ctx context.Context, name string) (*genai.BatchJob, error) {
+	delay := b.pollInterval
+	for {
+		job, err := b.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if batchJobDone(job.State) {
+			return job, batchJobErr(job)
+		}
+		b.logger.DebugContext(ctx, "Batch job pending",
+			slog.String("job", name),
+			slog.String("state", string(job.State)),
+			slog.Duration("next_poll", delay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return job, ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(delay*2, b.maxPollDelay)
+	}
+}
+
+// Results streams the per-request results of a finished generate-content job,
+// from inline responses or by downloading the output file.
+func (b *GeminiBatchClient) Results(ctx context.Context, job *genai.BatchJob) iter.Seq2[BatchResult, error] {
+	return func(yield func(BatchResult, error) bool) {
+		if job == nil || job.Dest == nil {
+			yield(BatchResult{}, fmt.Errorf("batch job has no results"))
+			return
+		}
+		if len(job.Dest.InlinedResponses) > 0 {
+			for _, result := range inlinedBatchResults(job.Dest.InlinedResponses) {
+				if !yield(result, nil) {
+					return
+				}
+			}
+			return
+		}
+		output, err := b.downloadOutput(ctx, job)
+		if err != nil {
+			yield(BatchResult{}, err)
+			return
+		}
+		defer output.Close()
+		for result, err := range parseBatchJSONL(output) {
+			if !yield(result, err) {
+				return
+			}
+		}
+	}
+}
+
+// EmbeddingResults streams the per-text results of a finished embedding job.
+func (b *GeminiBatchClient) EmbeddingResults(ctx context.Context, job *genai.BatchJob) iter.Seq2[EmbeddingBatchResult, error] {
+	return func(yield func(EmbeddingBatchResult, error) bool) {
+		if job == nil || job.Dest == nil {
+			yield(EmbeddingBatchResult{}, fmt.Errorf("batch job has no results"))
+			return
+		}
+		for i, resp := range job.Dest.InlinedEmbedContentResponses {
+			result := EmbeddingBatchResult{Index: i}
+			switch {
+			case resp == nil:
+				result.Err = fmt.Errorf("missing response")
+			case resp.Error != nil:
+				result.Err = jobErr(resp.Error)
+			case resp.Response == nil || resp.Response.Embedding == nil || len(resp.Response.Embedding.Values) == 0:
+				result.Err = fmt.Errorf("received empty embedding values from API")
+			default:
+				result.Values = resp.Response.Embedding.Values
+			}
+			if !yield(result, nil) {
+				return
+			}
+		}
+	}
+}
+
+// downloadOutput opens the job's output file for reading; the caller must
+// close it.
+func (b *GeminiBatchClient) downloadOutput(ctx context.Context, job *genai.BatchJob) (io.ReadCloser, error) {
+	if job.Dest.FileName == "" {
+		return nil, fmt.Errorf("batch job %s has no output file", job.Name)
+	}
+	req, err := newFileDownloadRequest(b.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download batch output: %w", err)
+	}
+	output, err := req.open(ctx, job.Dest.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download batch output: %w", err)
+	}
+	return output, nil
+}
+
+// BatchJobError reports a batch job that ended without succeeding.
+type BatchJobError struct {
+	Name    string
+	State   genai.JobState
+	Message string
+}
+
+func (e *BatchJobError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("batch job %s ended in state %s", e.Name, e.State)
+	}
+	return fmt.Sprintf("batch job %s ended in state %s: %s", e.Name, e.State, e.Message)
+}
+
+func batchJobDone(state genai.JobState) bool {
+	switch state {
+	case genai.JobStateSucceeded, genai.JobStatePartiallySucceeded,
+		genai.JobStateFailed, genai.JobStateCancelled, genai.JobStateExpired:
+		return true
+	}
+	return false
+}
+
+func batchJobErr(job *genai.BatchJob) error {
+	switch job.State {
+	case genai.JobStateFailed, genai.JobStateCancelled, genai.JobStateExpired:
+		err := &BatchJobError{Name: job.Name, State: job.State}
+		if job.Error != nil {
+			err.Message = job.Error.Message
+		}
+		return err
+	}
+	return nil
+}
+
+func jobErr(e *genai.JobError) error {
+	if e.Code == nil {
+		return fmt.Errorf("request failed: %s", e.Message)
+	}
+	return fmt.Errorf("request failed with code %d: %s", *e.Code, e.Message)
+}
+
+func inlinedBatchResults(responses []*genai.InlinedResponse) []BatchResult {
+	results := make([]BatchResult, len(responses))
+	for i, resp := range responses {
+		if resp == nil {
+			results[i].Err = fmt.Errorf("missing response at index %d", i)
+			continue
+		}
+		results[i].ID = resp.Metadata[batchKeyMetadata]
+		results[i].Response = resp.Response
+		if resp.Error != nil {
+			results[i].Err = jobErr(resp.Error)
+		}
+	}
+	return results
+}
+
+// batchLine is one line of a batch JSONL input or output file.
+type batchLine struct {
+	Key      string                         `json:"key"`
+	Request  map[string]any                 `json:"request,omitempty"`
+	Response *genai.GenerateContentResponse `json:"response,omitempty"`
+	Error    *genai.JobError                `json:"error,omitempty"`
+}
+
+// generateRequestTopLevel lists GenerateContentConfig fields that live at the
+// top level of a REST GenerateContentRequest; everything else belongs in its
+// generationConfig.
+var generateRequestTopLevel = map[string]bool{
+	"systemInstruction": true,
+	"tools":             true,
+	"toolConfig":        true,
+	"safetySettings":    true,
+	"cachedContent":     true,
+	"labels":            true,
+}
+
+// WriteBatchJSONL writes requests in the JSONL input format accepted by
+// SubmitJSONL.
+func WriteBatchJSONL(w io.Writer, requests []BatchRequest) error {
+	enc := json.NewEncoder(w)
+	for i, req := range requests {
+		if req.ID == "" {
+			return fmt.Errorf("request at index %d has no ID", i)
+		}
+		body, err := batchRequestBody(req)
+		if err != nil {
+			return fmt.Errorf("failed to encode request %q: %w", req.ID, err)
+		}
+		if err := enc.Encode(batchLine{Key: req.ID, Request: body}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func batchRequestBody(req BatchRequest) (map[string]any, error) {
+	body := map[string]any{"contents": req.contents()}
+	if req.Config == nil {
+		return body, nil
+	}
+	raw, err := json.Marshal(req.Config)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "httpOptions")
+	generationConfig := map[string]any{}
+	for k, v := range fields {
+		if generateRequestTopLevel[k] {
+			body[k] = v
+		} else {
+			generationConfig[k] = v
+		}
+	}
+	if len(generationConfig) > 0 {
+		body["generationConfig"] = generationConfig
+	}
+	return body, nil
+}
+
+func parseBatchJSONL(r io.Reader) iter.Seq2[BatchResult, error] {
+	return func(yield func(BatchResult, error) bool) {
+		scanner := bufio.NewScanner(r)
+		// Responses can be far larger than bufio's 64 KiB default.
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var line batchLine
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				if !yield(BatchResult{}, fmt.Errorf("invalid batch output at line %d: %w", lineNo, err)) {
+					return
+				}
+				continue
+			}
+			result := BatchResult{ID: line.Key, Response: line.Response}
+			if line.Error != nil {
+				result.Err = jobErr(line.Error)
+			}
+			if !yield(result, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(BatchResult{}, fmt.Errorf("failed to read batch output: %w", err))
+		}
+	}
+}
+
+var _ BatchClient = (*GeminiBatchClient)(nil)
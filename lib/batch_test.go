@@ -0,0 +1,161 @@
+package genai_sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestWriteBatchJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteBatchJSONL(&buf, []BatchRequest{
+		{
+			ID:     "poi-1",
+			Prompt: "Describe the Eiffel Tower",
+			Config: &genai.GenerateContentConfig{
+				Temperature:       genai.Ptr[float32](0.2),
+				SystemInstruction: genai.NewContentFromText("You are a guide", genai.RoleUser),
+			},
+		},
+		{ID: "poi-2", Prompt: "Describe the Louvre"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var first struct {
+		Key     string `json:"key"`
+		Request struct {
+			Contents          []any          `json:"contents"`
+			SystemInstruction map[string]any `json:"systemInstruction"`
+			GenerationConfig  map[string]any `json:"generationConfig"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("invalid JSONL line: %v", err)
+	}
+	if first.Key != "poi-1" || len(first.Request.Contents) != 1 {
+		t.Errorf("unexpected first line: %s", lines[0])
+	}
+	if first.Request.SystemInstruction == nil {
+		t.Error("systemInstruction should be at the request top level")
+	}
+	if first.Request.GenerationConfig["temperature"] != 0.2 {
+		t.Errorf("temperature should be in generationConfig, got %v", first.Request.GenerationConfig)
+	}
+}
+
+func TestWriteBatchJSONL_RequiresID(t *testing.T) {
+	if err := WriteBatchJSONL(&bytes.Buffer{}, []BatchRequest{{Prompt: "x"}}); err == nil {
+		t.Error("expected error for request without ID")
+	}
+}
+
+func TestParseBatchJSONL(t *testing.T) {
+	input := `{"key":"a","response":{"candidates":[{"content":{"parts":[{"text":"hello"}]}}]}}
+
+{"key":"b","error":{"code":400,"message":"bad request"}}
+not json
+`
+	var results []BatchResult
+	var errs int
+	for result, err := range parseBatchJSONL(strings.NewReader(input)) {
+		if err != nil {
+			errs++
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) != 2 || errs != 1 {
+		t.Fatalf("got %d results and %d errors, want 2 and 1", len(results), errs)
+	}
+	if text, err := ExtractText(results[0].Response); err != nil || text != "hello" || results[0].ID != "a" {
+		t.Errorf("first result = %+v, text %q, err %v", results[0], text, err)
+	}
+	if results[1].ID != "b" || results[1].Err == nil {
+		t.Errorf("second result should carry its per-request error: %+v", results[1])
+	}
+}
+
+func TestResults_StreamsOutputFile(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/files/out:download" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, `{"key":"a","response":{"candidates":[{"content":{"parts":[{"text":"hello"}]}}]}}`+"\n")
+		w.(http.Flusher).Flush()
+		// The second line is only sent once the first result has been seen.
+		<-release
+		_, _ = io.WriteString(w, `{"key":"b","error":{"code":400,"message":"bad request"}}`+"\n")
+	}))
+	defer srv.Close()
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-api-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &GeminiBatchClient{client: client}
+	job := &genai.BatchJob{Name: "batches/1", Dest: &genai.BatchJobDestination{FileName: "files/out"}}
+
+	var ids []string
+	for result, err := range b.Results(context.Background(), job) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) == 0 {
+			close(release)
+		}
+		ids = append(ids, result.ID)
+	}
+	if strings.Join(ids, ",") != "a,b" {
+		t.Errorf("ids = %v, want [a b]", ids)
+	}
+}
+
+func TestInlinedBatchResults(t *testing.T) {
+	results := inlinedBatchResults([]*genai.InlinedResponse{
+		{Metadata: map[string]string{batchKeyMetadata: "x"}, Response: &genai.GenerateContentResponse{}},
+		{Metadata: map[string]string{batchKeyMetadata: "y"}, Error: &genai.JobError{Message: "boom"}},
+	})
+	if results[0].ID != "x" || results[0].Err != nil {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+	if results[1].ID != "y" || results[1].Err == nil {
+		t.Errorf("unexpected second result: %+v", results[1])
+	}
+}
+
+func TestBatchJobErr(t *testing.T) {
+	if err := batchJobErr(&genai.BatchJob{State: genai.JobStateSucceeded}); err != nil {
+		t.Errorf("succeeded job should not error: %v", err)
+	}
+	err := batchJobErr(&genai.BatchJob{
+		Name:  "batches/1",
+		State: genai.JobStateFailed,
+		Error: &genai.JobError{Message: "quota"},
+	})
+	var jobErr *BatchJobError
+	if !errors.As(err, &jobErr) || jobErr.State != genai.JobStateFailed {
+		t.Errorf("expected BatchJobError, got %v", err)
+	}
+	if batchJobDone(genai.JobStateRunning) {
+		t.Error("running job should not be done")
+	}
+}
@@ -0,0 +1,264 @@
+package genai_sdk
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+)
+
+// Priority orders admission into a Bulkhead. Higher priorities are admitted
+// first whenever a slot frees up.
+type Priority int
+
+const (
+	// PriorityBatch is for background work such as embedding backfills.
+	PriorityBatch Priority = iota
+	// PriorityInteractive is for user-facing calls.
+	PriorityInteractive
+
+	numPriorities = int(PriorityInteractive) + 1
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityBatch:
+		return "batch"
+	case PriorityInteractive:
+		return "interactive"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+type priorityKey struct{}
+
+// WithPriority tags ctx with the admission priority used by bulkheads.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFrom(ctx context.Context, fallback Priority) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && int(p) < numPriorities {
+		return p
+	}
+	return fallback
+}
+
+// Bulkhead is a named concurrency pool shared by one or more clients. Waiting
+// calls are admitted by priority, then in arrival order, and a number of
+// slots can be reserved so that batch work never occupies the whole pool.
+type Bulkhead struct {
+	name     string
+	capacity int
+	reserved int
+
+	mu     sync.Mutex
+	inUse  int
+	queues [numPriorities][]*bulkheadWaiter
+	stats  BulkheadStats
+}
+
+type bulkheadWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// BulkheadStats is a snapshot of a Bulkhead's load.
+type BulkheadStats struct {
+	Name     string
+	Capacity int
+	InUse    int
+	// QueueDepth is the number of calls currently waiting, by Priority.
+	QueueDepth [numPriorities]int
+	// Admitted counts calls that acquired a slot, by Priority.
+	Admitted [numPriorities]int64
+	// TotalWait and MaxWait measure time spent queued before admission.
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// NewBulkhead creates a pool of capacity slots, reserved of which can only be
+// taken by PriorityInteractive calls.
+func NewBulkhead(name string, capacity, reserved int) (*Bulkhead, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("bulkhead capacity must be positive")
+	}
+	if reserved < 0 || reserved >= capacity {
+		return nil, fmt.Errorf("bulkhead reserved slots must be in [0, %d)", capacity)
+	}
+	return &Bulkhead{
+		name:     name,
+		capacity: capacity,
+		reserved: reserved,
+	}, nil
+}
+
+// Name returns the pool name.
+func (b *Bulkhead) Name() string {
+	return b.name
+}
+
+// Stats returns a snapshot of current usage and accumulated wait times.
+func (b *Bulkhead) Stats() BulkheadStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := b.stats
+	stats.Name = b.name
+	stats.Capacity = b.capacity
+	stats.InUse = b.inUse
+	for p, q := range b.queues {
+		stats.QueueDepth[p] = len(q)
+	}
+	return stats
+}
+
+// Acquire waits for a slot at priority p. The returned release func must be
+// called exactly once when the work is done.
+func (b *Bulkhead) Acquire(ctx context.Context, p Priority) (release func(), err error) {
+	if p < 0 || int(p) >= numPriorities {
+		return nil, fmt.Errorf("invalid priority %v", p)
+	}
+	start := time.Now()
+
+	b.mu.Lock()
+	if !b.queuedAtOrAbove(p) && b.canAdmit(p) {
+		b.admit(p, 0)
+		b.mu.Unlock()
+		return b.releaseOnce(), nil
+	}
+	w := &bulkheadWaiter{ready: make(chan struct{})}
+	b.queues[p] = append(b.queues[p], w)
+	b.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		b.mu.Lock()
+		b.recordWait(time.Since(start))
+		b.mu.Unlock()
+		return b.releaseOnce(), nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		if w.granted {
+			// Admitted concurrently with cancellation; hand the slot back.
+			b.inUse--
+			b.dispatch()
+		} else {
+			b.remove(p, w)
+		}
+		b.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// tryAcquire takes a slot at priority p only if one is free now and no call
+// at p or above is already waiting for it.
+func (b *Bulkhead) tryAcquire(p Priority) (release func(), ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.queuedAtOrAbove(p) || !b.canAdmit(p) {
+		return nil, false
+	}
+	b.admit(p, 0)
+	return b.releaseOnce(), true
+}
+
+func (b *Bulkhead) releaseOnce() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.inUse--
+			b.dispatch()
+		})
+	}
+}
+
+func (b *Bulkhead) queuedAtOrAbove(p Priority) bool {
+	for q := int(p); q < numPriorities; q++ {
+		if len(b.queues[q]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bulkhead) canAdmit(p Priority) bool {
+	limit := b.capacity
+	if p < PriorityInteractive {
+		limit -= b.reserved
+	}
+	return b.inUse < limit
+}
+
+func (b *Bulkhead) admit(p Priority, wait time.Duration) {
+	b.inUse++
+	b.stats.Admitted[p]++
+	b.recordWait(wait)
+}
+
+func (b *Bulkhead) recordWait(wait time.Duration) {
+	b.stats.TotalWait += wait
+	b.stats.MaxWait = max(b.stats.MaxWait, wait)
+}
+
+// dispatch hands free slots to waiters, highest priority first. Wait time for
+// these waiters is recorded by Acquire once they wake up.
+func (b *Bulkhead) dispatch() {
+	for p := numPriorities - 1; p >= 0; p-- {
+		for len(b.queues[p]) > 0 && b.canAdmit(Priority(p)) {
+			w := b.queues[p][0]
+			b.queues[p] = b.queues[p][1:]
+			w.granted = true
+			b.inUse++
+			b.stats.Admitted[p]++
+			close(w.ready)
+		}
+	}
+}
+
+func (b *Bulkhead) remove(p Priority, w *bulkheadWaiter) {
+	q := b.queues[p]
+	for i, other := range q {
+		if other == w {
+			b.queues[p] = append(q[:i:i], q[i+1:]...)
+			return
+		}
+	}
+}
+
+// withBulkhead runs fn inside a slot of b, or directly when b is nil.
+func withBulkhead[T any](ctx context.Context, b *Bulkhead, fallback Priority, fn func() (T, error)) (T, error) {
+	if b == nil {
+		return fn()
+	}
+	release, err := b.Acquire(ctx, priorityFrom(ctx, fallback))
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer release()
+	return fn()
+}
+
+// streamWithBulkhead holds a slot of b for as long as stream is being consumed.
+func streamWithBulkhead[T any](ctx context.Context, b *Bulkhead, fallback Priority, stream iter.Seq2[T, error]) iter.Seq2[T, error] {
+	if b == nil {
+		return stream
+	}
+	return func(yield func(T, error) bool) {
+		release, err := b.Acquire(ctx, priorityFrom(ctx, fallback))
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		defer release()
+		for v, err := range stream {
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewBulkhead_Validation(t *testing.T) {
+	if _, err := NewBulkhead("x", 0, 0); err == nil {
+		t.Error("expected error for zero capacity")
+	}
+	if _, err := NewBulkhead("x", 2, 2); err == nil {
+		t.Error("expected error when reserving the whole pool")
+	}
+}
+
+func TestBulkhead_InteractiveAdmittedBeforeBatch(t *testing.T) {
+	b, err := NewBulkhead("gemini", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	release, err := b.Acquire(ctx, PriorityBatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan Priority, 2)
+	acquire := func(p Priority) {
+		r, err := b.Acquire(ctx, p)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		order <- p
+		r()
+	}
+	go acquire(PriorityBatch)
+	waitForQueue(t, b, PriorityBatch, 1)
+	go acquire(PriorityInteractive)
+	waitForQueue(t, b, PriorityInteractive, 1)
+
+	release()
+	if first := <-order; first != PriorityInteractive {
+		t.Errorf("first admitted = %v, want interactive", first)
+	}
+	if second := <-order; second != PriorityBatch {
+		t.Errorf("second admitted = %v, want batch", second)
+	}
+
+	stats := b.Stats()
+	if stats.InUse != 0 || stats.Admitted[PriorityBatch] != 2 || stats.Admitted[PriorityInteractive] != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.MaxWait <= 0 {
+		t.Errorf("expected queued calls to record wait time, got %v", stats.MaxWait)
+	}
+}
+
+func TestBulkhead_ReservedSlotsExcludeBatch(t *testing.T) {
+	b, err := NewBulkhead("gemini", 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := b.Acquire(ctx, PriorityBatch); err != nil {
+		t.Fatal(err)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.Acquire(short, PriorityBatch); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second batch call should wait on reserved slot, got %v", err)
+	}
+	if depth := b.Stats().QueueDepth[PriorityBatch]; depth != 0 {
+		t.Errorf("cancelled waiter should leave the queue, depth = %d", depth)
+	}
+
+	if _, err := b.Acquire(ctx, PriorityInteractive); err != nil {
+		t.Errorf("interactive call should use reserved slot: %v", err)
+	}
+}
+
+func TestPriorityFrom(t *testing.T) {
+	ctx := context.Background()
+	if got := priorityFrom(ctx, PriorityBatch); got != PriorityBatch {
+		t.Errorf("fallback = %v, want batch", got)
+	}
+	if got := priorityFrom(WithPriority(ctx, PriorityInteractive), PriorityBatch); got != PriorityInteractive {
+		t.Errorf("context priority = %v, want interactive", got)
+	}
+}
+
+func waitForQueue(t *testing.T, b *Bulkhead, p Priority, depth int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for b.Stats().QueueDepth[p] != depth {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue %v never reached depth %d", p, depth)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,383 @@
+package genai_sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// DefaultCacheTTL is the lifetime given to cached contents created by a
+// CacheManager when no TTL is configured.
+const DefaultCacheTTL = time.Hour
+
+// After a failed create, a spec is not retried for cacheRetryBase, doubling
+// with each further failure up to cacheRetryMax.
+const (
+	cacheRetryBase = time.Minute
+	cacheRetryMax  = time.Hour
+)
+
+// CacheSpec describes a large, shared prompt prefix to keep in Gemini's
+// context cache: typically the system instruction plus static city context.
+type CacheSpec struct {
+	DisplayName       string
+	SystemInstruction *genai.Content
+	Contents          []*genai.Content
+	Tools             []*genai.Tool
+	ToolConfig        *genai.ToolConfig
+}
+
+// CacheManager creates and reuses cached contents on top of
+// genai.Client.Caches. Identical specs for the same model share one cache,
+// whose TTL is extended shortly before it expires.
+type CacheManager struct {
+	client        *genai.Client
+	model         string
+	ttl           time.Duration
+	refreshBefore time.Duration
+	logger        *slog.Logger
+	now           func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is guarded by CacheManager.mu. API calls are made without the
+// lock; pending is set while one is in flight so that other callers wait for
+// it instead of repeating it.
+type cacheEntry struct {
+	content *genai.CachedContent
+	pending chan struct{}
+
+	// createErr is the last failed create, not retried before retryAt.
+	createErr error
+	failures  int
+	retryAt   time.Time
+}
+
+// NewCacheManager creates a CacheManager for the chat client's model. ttl
+// defaults to DefaultCacheTTL; caches are refreshed when less than a tenth of
+// the TTL remains.
+func NewCacheManager(chat *GeminiChatClient, ttl time.Duration) *CacheManager {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &CacheManager{
+		client:        chat.client,
+		model:         chat.model,
+		ttl:           ttl,
+		refreshBefore: ttl / 10,
+		logger:        chat.logger,
+		now:           time.Now,
+		entries:       make(map[string]*cacheEntry),
+	}
+}
+
+// Ensure returns a live cached content for spec, creating it or extending its
+// TTL as needed. Concurrent calls for the same spec share one API call. A
+// failed create is remembered, and Ensure returns that error without calling
+// the API until a backoff of one minute, doubling up to an hour, has passed.
+func (m *CacheManager) Ensure(ctx context.Context, spec CacheSpec) (*genai.CachedContent, error) {
+	key, err := m.key(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	entry := m.entry(key)
+	for entry.pending != nil {
+		if err := m.waitIdle(ctx, entry); err != nil {
+			m.mu.Unlock()
+			return nil, err
+		}
+		// The entry may have been deleted or evicted while we waited.
+		entry = m.entry(key)
+	}
+
+	now := m.now()
+	current := entry.content
+	if current != nil {
+		remaining := current.ExpireTime.Sub(now)
+		if remaining > m.refreshBefore {
+			m.mu.Unlock()
+			return current, nil
+		}
+		if remaining <= 0 {
+			current = nil
+		}
+	}
+	if current == nil && entry.createErr != nil && now.Before(entry.retryAt) {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("context cache unavailable until %s: %w", entry.retryAt.Format(time.RFC3339), entry.createErr)
+	}
+	pending := make(chan struct{})
+	entry.pending = pending
+	m.mu.Unlock()
+
+	content, created, err := m.refreshOrCreate(ctx, spec, current, now)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.pending = nil
+	close(pending)
+	switch {
+	case err == nil:
+		entry.content = content
+		entry.createErr, entry.failures, entry.retryAt = nil, 0, time.Time{}
+	case created:
+		entry.content = nil
+		if ctx.Err() == nil {
+			entry.createErr = err
+			entry.failures++
+			entry.retryAt = now.Add(cacheRetryDelay(entry.failures))
+		}
+	}
+	return content, err
+}
+
+// refreshOrCreate extends the TTL of current, or creates a new cached content
+// when current is nil or no longer exists. created reports whether a create
+// was attempted.
+func (m *CacheManager) refreshOrCreate(ctx context.Context, spec CacheSpec, current *genai.CachedContent, now time.Time) (content *genai.CachedContent, created bool, err error) {
+	if current != nil {
+		refreshed, err := m.client.Caches.Update(ctx, current.Name, &genai.UpdateCachedContentConfig{TTL: m.ttl})
+		if err == nil {
+			m.logger.DebugContext(ctx, "Cached content refreshed",
+				slog.String("cache", refreshed.Name),
+				slog.Time("expire_time", refreshed.ExpireTime))
+			return refreshed, false, nil
+		}
+		if !errors.Is(ClassifyError(err), ErrNotFound) {
+			return nil, false, fmt.Errorf("failed to refresh cached content: %w", ClassifyError(err))
+		}
+	}
+
+	content, err = m.client.Caches.Create(ctx, m.model, &genai.CreateCachedContentConfig{
+		TTL:               m.ttl,
+		DisplayName:       spec.DisplayName,
+		SystemInstruction: spec.SystemInstruction,
+		Contents:          spec.Contents,
+		Tools:             spec.Tools,
+		ToolConfig:        spec.ToolConfig,
+	})
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to create cached content: %w", ClassifyError(err))
+	}
+	if content.ExpireTime.IsZero() {
+		content.ExpireTime = now.Add(m.ttl)
+	}
+	m.logger.InfoContext(ctx, "Cached content created",
+		slog.String("cache", content.Name),
+		slog.String("model", m.model),
+		slog.Time("expire_time", content.ExpireTime))
+	return content, true, nil
+}
+
+// entry returns the entry for key, adding one if needed. Adding an entry
+// first evicts those that hold nothing worth keeping: no live cached content
+// and no create backoff still running. Callers must hold m.mu.
+func (m *CacheManager) entry(key string) *cacheEntry {
+	if entry, ok := m.entries[key]; ok {
+		return entry
+	}
+	now := m.now()
+	for k, e := range m.entries {
+		if e.pending == nil && (e.content == nil || !e.content.ExpireTime.After(now)) && !now.Before(e.retryAt) {
+			delete(m.entries, k)
+		}
+	}
+	entry := &cacheEntry{}
+	m.entries[key] = entry
+	return entry
+}
+
+// waitIdle waits for the API call in flight on entry to finish. Callers must
+// hold m.mu, which is released while waiting and held again on return.
+func (m *CacheManager) waitIdle(ctx context.Context, entry *cacheEntry) error {
+	pending := entry.pending
+	m.mu.Unlock()
+	defer m.mu.Lock()
+	select {
+	case <-pending:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Apply returns a copy of cfg that uses the cached content for spec. The
+// system instruction, tools and tool config are held by the cache and are
+// cleared, since the API rejects requests that repeat them alongside
+// CachedContent; Apply returns an error if cfg sets any of them to something
+// other than what spec holds.
+func (m *CacheManager) Apply(ctx context.Context, spec CacheSpec, cfg *genai.GenerateContentConfig) (*genai.GenerateContentConfig, error) {
+	if err := checkCacheConflict(spec, cfg); err != nil {
+		return nil, err
+	}
+	cached, err := m.Ensure(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	var out genai.GenerateContentConfig
+	if cfg != nil {
+		out = *cfg
+	}
+	out.CachedContent = cached.Name
+	out.SystemInstruction = nil
+	out.Tools = nil
+	out.ToolConfig = nil
+	return &out, nil
+}
+
+// checkCacheConflict reports fields of cfg that the cached content would
+// silently replace.
+func checkCacheConflict(spec CacheSpec, cfg *genai.GenerateContentConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	switch {
+	case cfg.SystemInstruction != nil && !reflect.DeepEqual(cfg.SystemInstruction, spec.SystemInstruction):
+		return fmt.Errorf("config system instruction conflicts with the context cache")
+	case cfg.Tools != nil && !reflect.DeepEqual(cfg.Tools, spec.Tools):
+		return fmt.Errorf("config tools conflict with the context cache")
+	case cfg.ToolConfig != nil && !reflect.DeepEqual(cfg.ToolConfig, spec.ToolConfig):
+		return fmt.Errorf("config tool config conflicts with the context cache")
+	}
+	return nil
+}
+
+// cacheRetryDelay is the backoff after the given number of failed creates.
+func cacheRetryDelay(failures int) time.Duration {
+	delay := cacheRetryBase
+	for i := 1; i < failures && delay < cacheRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, cacheRetryMax)
+}
+
+// Delete removes the cached content for spec, if one was created.
+func (m *CacheManager) Delete(ctx context.Context, spec CacheSpec) error {
+	key, err := m.key(spec)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	entry, ok := m.entries[key]
+	delete(m.entries, key)
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return m.deleteEntry(ctx, entry)
+}
+
+// DeleteAll removes every cached content created by this manager.
+func (m *CacheManager) DeleteAll(ctx context.Context) error {
+	m.mu.Lock()
+	entries := m.entries
+	m.entries = make(map[string]*cacheEntry)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		if err := m.deleteEntry(ctx, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *CacheManager) deleteEntry(ctx context.Context, entry *cacheEntry) error {
+	m.mu.Lock()
+	for entry.pending != nil {
+		if err := m.waitIdle(ctx, entry); err != nil {
+			m.mu.Unlock()
+			return err
+		}
+	}
+	content := entry.content
+	entry.content = nil
+	m.mu.Unlock()
+	if content == nil {
+		return nil
+	}
+	_, err := m.client.Caches.Delete(ctx, content.Name, nil)
+	if err != nil && !errors.Is(ClassifyError(err), ErrNotFound) {
+		return fmt.Errorf("failed to delete cached content %s: %w", content.Name, ClassifyError(err))
+	}
+	return nil
+}
+
+// key hashes the model and everything the cache holds, so that identical
+// specs map to the same cached content.
+func (m *CacheManager) key(spec CacheSpec) (string, error) {
+	raw, err := json.Marshal(struct {
+		Model string
+		Spec  CacheSpec
+	}{m.model, spec})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash cache spec: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// WithContextCache makes Generate, GenerateText, GenerateStream and
+// StartChatSession use cached content for spec. If the cache cannot be
+// created (for example because the spec is below the model's minimum
+// cacheable size) calls fall back to sending the spec inline.
+func (g *GeminiChatClient) WithContextCache(manager *CacheManager, spec CacheSpec) *GeminiChatClient {
+	g.cacheManager = manager
+	g.cacheSpec = spec
+	return g
+}
+
+// withCachedContext returns the request contents and config to send for
+// prompt, routed through the context cache when one is configured.
+func (g *GeminiChatClient) withCachedContext(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig) ([]*genai.Content, *genai.GenerateContentConfig) {
+	return g.withCachedContextFor(ctx, g.model, contents, config)
+}
+
+// withCachedContextFor is withCachedContext for a request to model. Cached
+// contents belong to the manager's model, so other models get the spec
+// inline.
+func (g *GeminiChatClient) withCachedContextFor(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) ([]*genai.Content, *genai.GenerateContentConfig) {
+	if g.cacheManager == nil {
+		return contents, config
+	}
+	if model == g.cacheManager.model {
+		cached, err := g.cacheManager.Apply(ctx, g.cacheSpec, config)
+		if err == nil {
+			return contents, cached
+		}
+		g.logger.WarnContext(ctx, "context cache unavailable; sending prompt inline",
+			slog.String("error", err.Error()))
+	}
+
+	var inline genai.GenerateContentConfig
+	if config != nil {
+		inline = *config
+	}
+	if inline.SystemInstruction == nil {
+		inline.SystemInstruction = g.cacheSpec.SystemInstruction
+	}
+	if inline.Tools == nil {
+		inline.Tools = g.cacheSpec.Tools
+	}
+	if inline.ToolConfig == nil {
+		inline.ToolConfig = g.cacheSpec.ToolConfig
+	}
+	prefixed := make([]*genai.Content, 0, len(g.cacheSpec.Contents)+len(contents))
+	prefixed = append(prefixed, g.cacheSpec.Contents...)
+	prefixed = append(prefixed, contents...)
+	return prefixed, &inline
+}
@@ -0,0 +1,231 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func newTestCacheManager(t *testing.T) *CacheManager {
+	t.Helper()
+	chat, err := NewGeminiChatClient(context.Background(), "test-api-key", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return NewCacheManager(chat.(*GeminiChatClient), time.Hour)
+}
+
+func TestCacheManager_KeyIsContentAddressed(t *testing.T) {
+	m := newTestCacheManager(t)
+	spec := func(city string) CacheSpec {
+		return CacheSpec{
+			SystemInstruction: genai.NewContentFromText("You are a travel guide", genai.RoleUser),
+			Contents:          genai.Text("City context: " + city),
+		}
+	}
+
+	a, err := m.key(spec("Lisbon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := m.key(spec("Lisbon"))
+	c, _ := m.key(spec("Porto"))
+	if a != b {
+		t.Error("identical specs should share a cache key")
+	}
+	if a == c {
+		t.Error("different contents should not share a cache key")
+	}
+}
+
+func TestCacheManager_ApplyReusesLiveCache(t *testing.T) {
+	m := newTestCacheManager(t)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	spec := CacheSpec{
+		SystemInstruction: genai.NewContentFromText("You are a travel guide", genai.RoleUser),
+		Contents:          genai.Text("City context: Lisbon"),
+	}
+	key, _ := m.key(spec)
+	m.entries[key] = &cacheEntry{content: &genai.CachedContent{
+		Name:       "cachedContents/abc",
+		ExpireTime: now.Add(30 * time.Minute),
+	}}
+
+	in := &genai.GenerateContentConfig{
+		Temperature:       genai.Ptr[float32](0.3),
+		SystemInstruction: spec.SystemInstruction,
+	}
+	out, err := m.Apply(context.Background(), spec, in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.CachedContent != "cachedContents/abc" {
+		t.Errorf("CachedContent = %q, want cachedContents/abc", out.CachedContent)
+	}
+	if out.SystemInstruction != nil {
+		t.Error("system instruction held by the cache should be cleared")
+	}
+	if out.Temperature == nil || *out.Temperature != 0.3 {
+		t.Error("other config fields should be preserved")
+	}
+	if in.CachedContent != "" || in.SystemInstruction == nil {
+		t.Error("Apply must not mutate the caller's config")
+	}
+}
+
+func TestCacheManager_RemembersCreateFailure(t *testing.T) {
+	m := newTestCacheManager(t)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	spec := CacheSpec{Contents: genai.Text("too short to cache")}
+	key, _ := m.key(spec)
+	createErr := &Error{Kind: ErrInvalidArgument, Code: 400}
+	m.entries[key] = &cacheEntry{createErr: createErr, failures: 1, retryAt: now.Add(time.Minute)}
+
+	// Within the backoff the remembered error is returned without a request.
+	if _, err := m.Ensure(context.Background(), spec); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected remembered ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestCacheManager_EnsureDoesNotBlockOnCreate(t *testing.T) {
+	var creates atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		creates.Add(1)
+		<-release
+		_, _ = io.WriteString(w, `{"name":"cachedContents/abc","expireTime":"2099-01-01T00:00:00Z"}`)
+	}))
+	defer srv.Close()
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-api-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewCacheManager(&GeminiChatClient{client: client, model: "gemini-2.5-flash", logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, time.Hour)
+	spec := CacheSpec{Contents: genai.Text("City context: Lisbon")}
+
+	var wg sync.WaitGroup
+	names := make([]string, 2)
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if cached, err := m.Ensure(context.Background(), spec); err == nil {
+				names[i] = cached.Name
+			}
+		}()
+	}
+	waitFor(t, func() bool { return creates.Load() == 1 })
+
+	// A caller waiting on the create in flight can still give up.
+	short, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Ensure(short, spec); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded while the create is in flight, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	if n := creates.Load(); n != 1 {
+		t.Errorf("sent %d creates, want 1 shared create", n)
+	}
+	if names[0] != "cachedContents/abc" || names[1] != "cachedContents/abc" {
+		t.Errorf("names = %v, want both callers to get the shared cache", names)
+	}
+}
+
+func TestCacheManager_EvictsDeadEntries(t *testing.T) {
+	m := newTestCacheManager(t)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	live := &cacheEntry{content: &genai.CachedContent{Name: "cachedContents/live", ExpireTime: now.Add(time.Hour)}}
+	backingOff := &cacheEntry{createErr: errors.New("boom"), failures: 1, retryAt: now.Add(time.Minute)}
+	m.entries["live"] = live
+	m.entries["backing-off"] = backingOff
+	m.entries["expired"] = &cacheEntry{content: &genai.CachedContent{Name: "cachedContents/old", ExpireTime: now.Add(-time.Minute)}}
+	m.entries["backoff-over"] = &cacheEntry{createErr: errors.New("boom"), failures: 1, retryAt: now.Add(-time.Second)}
+
+	m.mu.Lock()
+	m.entry("new")
+	m.mu.Unlock()
+
+	if len(m.entries) != 3 || m.entries["live"] != live || m.entries["backing-off"] != backingOff || m.entries["new"] == nil {
+		t.Errorf("entries after eviction = %v, want live, backing-off and new", m.entries)
+	}
+}
+
+func TestCacheRetryDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{7, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := cacheRetryDelay(tt.failures); got != tt.want {
+			t.Errorf("cacheRetryDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestCacheManager_ApplyRejectsConflictingConfig(t *testing.T) {
+	m := newTestCacheManager(t)
+	spec := CacheSpec{SystemInstruction: genai.NewContentFromText("You are a travel guide", genai.RoleUser)}
+	cfg := &genai.GenerateContentConfig{SystemInstruction: genai.NewContentFromText("You are a pirate", genai.RoleUser)}
+	if _, err := m.Apply(context.Background(), spec, cfg); err == nil {
+		t.Error("expected error for a system instruction the cache would replace")
+	}
+	cfg = &genai.GenerateContentConfig{Tools: []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}}
+	if _, err := m.Apply(context.Background(), spec, cfg); err == nil {
+		t.Error("expected error for tools the cache would replace")
+	}
+}
+
+func TestWithCachedContextFor_OtherModelGoesInline(t *testing.T) {
+	chat, err := NewGeminiChatClient(context.Background(), "test-api-key", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := chat.(*GeminiChatClient)
+	m := NewCacheManager(g, time.Hour)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	spec := CacheSpec{
+		SystemInstruction: genai.NewContentFromText("You are a travel guide", genai.RoleUser),
+		Contents:          genai.Text("City context: Lisbon"),
+	}
+	key, _ := m.key(spec)
+	m.entries[key] = &cacheEntry{content: &genai.CachedContent{Name: "cachedContents/abc", ExpireTime: now.Add(30 * time.Minute)}}
+	g.WithContextCache(m, spec)
+
+	_, cfg := g.withCachedContextFor(context.Background(), "gemini-2.5-flash", genai.Text("hi"), nil)
+	if cfg.CachedContent != "cachedContents/abc" {
+		t.Errorf("same model CachedContent = %q", cfg.CachedContent)
+	}
+
+	contents, cfg := g.withCachedContextFor(context.Background(), "gemini-2.5-flash-lite", genai.Text("hi"), nil)
+	if cfg.CachedContent != "" || cfg.SystemInstruction != spec.SystemInstruction || len(contents) != 2 {
+		t.Errorf("hedge model got CachedContent %q, %d contents; want the spec inline", cfg.CachedContent, len(contents))
+	}
+}
@@ -0,0 +1,102 @@
+package genai_sdk
+
+import (
+	"fmt"
+	"slices"
+
+	"google.golang.org/genai"
+)
+
+// CandidateResult is one response candidate with the metadata needed to rank
+// candidates or run self-consistency voting.
+type CandidateResult struct {
+	Index int
+	// Text is the answer text; thought summaries are in Thoughts.
+	Text          string
+	Thoughts      string
+	FinishReason  genai.FinishReason
+	FinishMessage string
+	SafetyRatings []*genai.SafetyRating
+	// AvgLogprobs is the average log probability of the candidate's tokens.
+	AvgLogprobs float64
+	// Tokens holds per-token log probabilities when ResponseLogprobs was set.
+	Tokens []TokenLogprob
+}
+
+// TokenLogprob is the log probability of a chosen token and, when Logprobs
+// was set, the most likely alternatives at that position.
+type TokenLogprob struct {
+	Token          string
+	TokenID        int32
+	LogProbability float32
+	Alternatives   []TokenLogprob
+}
+
+// WithCandidates returns a copy of cfg (which may be nil) asking for count
+// candidates. topLogprobs > 0 also requests per-token log probabilities with
+// that many alternatives per position.
+func WithCandidates(cfg *genai.GenerateContentConfig, count, topLogprobs int32) *genai.GenerateContentConfig {
+	var out genai.GenerateContentConfig
+	if cfg != nil {
+		out = *cfg
+	}
+	out.CandidateCount = count
+	if topLogprobs > 0 {
+		out.ResponseLogprobs = true
+		out.Logprobs = genai.Ptr(topLogprobs)
+	}
+	return &out
+}
+
+// ExtractCandidates returns every candidate in resp, ordered by candidate
+// index. Candidates without text (for example ones stopped by safety filters)
+// are included so callers can inspect why they finished.
+func ExtractCandidates(resp *genai.GenerateContentResponse) ([]CandidateResult, error) {
+	if resp == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	results := make([]CandidateResult, 0, len(resp.Candidates))
+	for _, cand := range resp.Candidates {
+		if cand == nil {
+			continue
+		}
+		thoughts, text := splitParts(cand.Content)
+		results = append(results, CandidateResult{
+			Index:         int(cand.Index),
+			Text:          text,
+			Thoughts:      thoughts,
+			FinishReason:  cand.FinishReason,
+			FinishMessage: cand.FinishMessage,
+			SafetyRatings: cand.SafetyRatings,
+			AvgLogprobs:   cand.AvgLogprobs,
+			Tokens:        tokenLogprobs(cand.LogprobsResult),
+		})
+	}
+	slices.SortStableFunc(results, func(a, b CandidateResult) int { return a.Index - b.Index })
+	return results, nil
+}
+
+func tokenLogprobs(lr *genai.LogprobsResult) []TokenLogprob {
+	if lr == nil || len(lr.ChosenCandidates) == 0 {
+		return nil
+	}
+	tokens := make([]TokenLogprob, len(lr.ChosenCandidates))
+	for i, chosen := range lr.ChosenCandidates {
+		if chosen != nil {
+			tokens[i] = TokenLogprob{Token: chosen.Token, TokenID: chosen.TokenID, LogProbability: chosen.LogProbability}
+		}
+		if i < len(lr.TopCandidates) && lr.TopCandidates[i] != nil {
+			for _, alt := range lr.TopCandidates[i].Candidates {
+				if alt != nil {
+					tokens[i].Alternatives = append(tokens[i].Alternatives,
+						TokenLogprob{Token: alt.Token, TokenID: alt.TokenID, LogProbability: alt.LogProbability})
+				}
+			}
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,69 @@
+package genai_sdk
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestExtractCandidates(t *testing.T) {
+	resp := &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			{
+				Index:        1,
+				Content:      &genai.Content{Parts: []*genai.Part{{Text: "Porto"}}},
+				FinishReason: genai.FinishReasonStop,
+				AvgLogprobs:  -0.7,
+			},
+			{
+				Index:        0,
+				Content:      &genai.Content{Parts: []*genai.Part{{Text: "Lisbon"}}},
+				FinishReason: genai.FinishReasonStop,
+				AvgLogprobs:  -0.2,
+				LogprobsResult: &genai.LogprobsResult{
+					ChosenCandidates: []*genai.LogprobsResultCandidate{{Token: "Lisbon", LogProbability: -0.2}},
+					TopCandidates: []*genai.LogprobsResultTopCandidates{{
+						Candidates: []*genai.LogprobsResultCandidate{
+							{Token: "Lisbon", LogProbability: -0.2},
+							{Token: "Porto", LogProbability: -1.8},
+						},
+					}},
+				},
+			},
+			{Index: 2, FinishReason: genai.FinishReasonSafety},
+		},
+	}
+
+	got, err := ExtractCandidates(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d candidates, want 3", len(got))
+	}
+	if got[0].Text != "Lisbon" || got[1].Text != "Porto" {
+		t.Errorf("candidates not ordered by index: %+v", got)
+	}
+	if len(got[0].Tokens) != 1 || len(got[0].Tokens[0].Alternatives) != 2 {
+		t.Errorf("unexpected token logprobs: %+v", got[0].Tokens)
+	}
+	if got[2].Text != "" || got[2].FinishReason != genai.FinishReasonSafety {
+		t.Errorf("blocked candidate should be reported: %+v", got[2])
+	}
+}
+
+func TestExtractCandidates_Empty(t *testing.T) {
+	if _, err := ExtractCandidates(&genai.GenerateContentResponse{}); err == nil {
+		t.Error("expected error for response without candidates")
+	}
+}
+
+func TestWithCandidates(t *testing.T) {
+	cfg := WithCandidates(nil, 3, 5)
+	if cfg.CandidateCount != 3 || !cfg.ResponseLogprobs || cfg.Logprobs == nil || *cfg.Logprobs != 5 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if plain := WithCandidates(nil, 2, 0); plain.ResponseLogprobs {
+		t.Error("logprobs should only be requested when topLogprobs > 0")
+	}
+}
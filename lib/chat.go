@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"iter"
 	"log/slog"
+	"time"
 
 	"google.golang.org/genai"
 )
@@ -24,7 +25,15 @@ type GeminiChatClient struct {
 	client      *genai.Client
 	model       string
 	retryPolicy RetryPolicy
+	hedgePolicy HedgePolicy
+	bulkhead    *Bulkhead
 	logger      *slog.Logger
+
+	cacheManager *CacheManager
+	cacheSpec    CacheSpec
+
+	filePollInterval time.Duration
+	fileMaxPollDelay time.Duration
 }
 
 // NewGeminiChatClient creates a ChatClient backed by Gemini.
@@ -53,6 +62,13 @@ func (g *GeminiChatClient) WithRetryPolicy(policy RetryPolicy) *GeminiChatClient
 	return g
 }
 
+// WithBulkhead routes generate calls through b. Calls default to
+// PriorityInteractive unless the context carries another priority.
+func (g *GeminiChatClient) WithBulkhead(b *Bulkhead) *GeminiChatClient {
+	g.bulkhead = b
+	return g
+}
+
 // WithLogger sets the logger used for retry diagnostics.
 func (g *GeminiChatClient) WithLogger(logger *slog.Logger) *GeminiChatClient {
 	if logger != nil {
@@ -62,9 +78,16 @@ func (g *GeminiChatClient) WithLogger(logger *slog.Logger) *GeminiChatClient {
 }
 
 func (g *GeminiChatClient) Generate(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	input := genai.Text(prompt)
+	contents, cachedConfig := g.withCachedContext(ctx, input, config)
 	return retryWithBackoff(ctx, g.retryPolicy, g.logger, "Generate",
-		func() (*genai.GenerateContentResponse, error) {
-			return g.client.Models.GenerateContent(ctx, g.model, genai.Text(prompt), config)
+		func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+			return withBulkhead(ctx, g.bulkhead, PriorityInteractive, func() (*genai.GenerateContentResponse, error) {
+				if g.hedgePolicy.Delay > 0 {
+					return g.generateHedged(ctx, contents, cachedConfig, input, config)
+				}
+				return g.client.Models.GenerateContent(ctx, g.model, contents, cachedConfig)
+			})
 		})
 }
 
@@ -77,10 +100,13 @@ func (g *GeminiChatClient) GenerateText(ctx context.Context, prompt string, conf
 }
 
 func (g *GeminiChatClient) GenerateStream(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+	contents, config := g.withCachedContext(ctx, genai.Text(prompt), config)
 	return retryWithBackoff(ctx, g.retryPolicy, g.logger, "GenerateStream",
-		func() (iter.Seq2[*genai.GenerateContentResponse, error], error) {
-			stream := g.client.Models.GenerateContentStream(ctx, g.model, genai.Text(prompt), config)
-			return stream, nil
+		// The stream is consumed after this returns, so it must not be bound
+		// to the per-attempt context.
+		func(context.Context) (iter.Seq2[*genai.GenerateContentResponse, error], error) {
+			stream := g.client.Models.GenerateContentStream(ctx, g.model, contents, config)
+			return streamWithBulkhead(ctx, g.bulkhead, PriorityInteractive, stream), nil
 		})
 }
 
@@ -100,9 +126,12 @@ type ChatSession struct {
 }
 
 func (g *GeminiChatClient) StartChatSession(ctx context.Context, config *genai.GenerateContentConfig) (*ChatSession, error) {
-	chat, err := g.client.Chats.Create(ctx, g.model, config, nil)
+	// With a context cache the cached contents play the role of history; if
+	// the cache is unavailable they are sent inline as history instead.
+	history, config := g.withCachedContext(ctx, nil, config)
+	chat, err := g.client.Chats.Create(ctx, g.model, config, history)
 	if err != nil {
-		return nil, err
+		return nil, ClassifyError(err)
 	}
 	return &ChatSession{chat: chat}, nil
 }
@@ -110,7 +139,7 @@ func (g *GeminiChatClient) StartChatSession(ctx context.Context, config *genai.G
 func (cs *ChatSession) SendMessage(ctx context.Context, message string) (string, error) {
 	result, err := cs.chat.SendMessage(ctx, genai.Part{Text: message})
 	if err != nil {
-		return "", err
+		return "", ClassifyError(err)
 	}
 	return ExtractText(result)
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 
 	"google.golang.org/genai"
@@ -13,25 +15,39 @@ const (
 	// Gemini embedding model - using the latest embedding model
 	//EmbeddingModel = "text-embedding-004"
 	EmbeddingModel = "gemini-embedding-exp-03-07"
-	// Standard embedding dimension for Gemini text-embedding-004
+	// EmbeddingDimension is the default output dimensionality; see
+	// WithDimension.
 	EmbeddingDimension = 768
 )
 
 // EmbeddingClient abstracts embedding operations needed by domain services.
+// The domain methods pick the task type for their use; GenerateEmbedding and
+// BatchGenerateEmbeddingsWithConfig let callers choose it per call.
 type EmbeddingClient interface {
+	GenerateEmbedding(ctx context.Context, text string, config *genai.EmbedContentConfig) ([]float32, error)
 	GenerateQueryEmbedding(ctx context.Context, query string) ([]float32, error)
 	GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error)
 	GenerateCityEmbedding(ctx context.Context, name, country, description string) ([]float32, error)
 	GenerateUserPreferenceEmbedding(ctx context.Context, interests []string, preferences map[string]string) ([]float32, error)
 	BatchGenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
+	BatchGenerateEmbeddingsWithConfig(ctx context.Context, texts []string, config *genai.EmbedContentConfig) ([][]float32, error)
+	BatchGenerateDocumentEmbeddings(ctx context.Context, docs []EmbeddingInput) ([][]float32, error)
 	Close()
 }
 
 // GeminiEmbeddingClient adapts the generativeAI embedding service.
 type GeminiEmbeddingClient struct {
-	client *genai.Client
-	model  string
-	logger *slog.Logger
+	client      *genai.Client
+	model       string
+	bulkhead    *Bulkhead
+	logger      *slog.Logger
+	retryPolicy RetryPolicy
+	batchLimits EmbeddingBatchLimits
+	dimension   int
+
+	pool         EmbeddingPoolOptions
+	requestLimit *minuteLimiter
+	tokenLimit   *minuteLimiter
 }
 
 // NewGeminiEmbeddingClient creates an EmbeddingClient backed by Gemini.
@@ -56,12 +72,33 @@ func NewGeminiEmbeddingClient(ctx context.Context, apiKey, embeddingModel string
 	}
 
 	return &GeminiEmbeddingClient{
-		client: client,
-		model:  embeddingModel,
-		logger: logger,
+		client:      client,
+		model:       embeddingModel,
+		logger:      logger,
+		retryPolicy: DefaultRetryPolicy,
+		batchLimits: DefaultEmbeddingBatchLimits,
+		dimension:   EmbeddingDimension,
 	}, nil
 }
 
+// WithBulkhead routes embedding calls through b. Calls default to
+// PriorityBatch, except GenerateQueryEmbedding which serves user searches and
+// defaults to PriorityInteractive; a priority on the context overrides both.
+func (es *GeminiEmbeddingClient) WithBulkhead(b *Bulkhead) *GeminiEmbeddingClient {
+	es.bulkhead = b
+	return es
+}
+
+// Model returns the embedding model name.
+func (es *GeminiEmbeddingClient) Model() string {
+	return es.model
+}
+
+// Dimension returns the configured output dimensionality, or 0 if unchecked.
+func (es *GeminiEmbeddingClient) Dimension() int {
+	return es.dimension
+}
+
 // Close provides a noop closer to align with consumers expecting a cleanup hook.
 func (es *GeminiEmbeddingClient) Close() {
 	if es == nil {
@@ -69,19 +106,29 @@ func (es *GeminiEmbeddingClient) Close() {
 	}
 }
 
-// GenerateEmbedding generates an embedding vector for the given text
+// GenerateEmbedding generates an embedding vector for the given text. An
+// empty task type is left unset for the model's default, unless config has a
+// title, which makes it EmbeddingTaskRetrievalDocument.
 func (es *GeminiEmbeddingClient) GenerateEmbedding(ctx context.Context, text string, config *genai.EmbedContentConfig) ([]float32, error) {
 	if text == "" {
 		return nil, fmt.Errorf("text cannot be empty")
 	}
-
-	// Use the embedding model to generate embeddings
-	embedding, err := es.client.Models.EmbedContent(ctx, es.model, genai.Text(text), config)
+	config = es.withDimension(embeddingConfig(config, "", ""))
+
+	// Use the embedding model to generate embeddings. Single calls share the
+	// worker pool's rate limits with batches.
+	err := es.waitRateLimit(ctx, estimateTokens(text))
+	var embedding *genai.EmbedContentResponse
+	if err == nil {
+		embedding, err = withBulkhead(ctx, es.bulkhead, PriorityBatch, func() (*genai.EmbedContentResponse, error) {
+			return es.client.Models.EmbedContent(ctx, es.model, genai.Text(text), config)
+		})
+	}
 	if err != nil {
 		es.logger.ErrorContext(ctx, "Failed to generate embedding",
 			slog.Any("error", err),
 			slog.String("text_preview", text[:min(100, len(text))]))
-		return nil, fmt.Errorf("failed to generate embedding: %w", err)
+		return nil, fmt.Errorf("failed to generate embedding: %w", ClassifyError(err))
 	}
 
 	// Extract the embedding values
@@ -95,28 +142,44 @@ func (es *GeminiEmbeddingClient) GenerateEmbedding(ctx context.Context, text str
 		return nil, fmt.Errorf("received empty embedding values from API")
 	}
 
+	values, err := es.fitDimension(contentEmbedding.Values, config)
+	if err != nil {
+		return nil, err
+	}
+
 	es.logger.DebugContext(ctx, "Embedding generated",
-		slog.Int("dimension", len(contentEmbedding.Values)),
+		slog.Int("dimension", len(values)),
 		slog.String("model", es.model))
 
-	return contentEmbedding.Values, nil
+	return values, nil
 }
 
-// GeneratePOIEmbedding generates an embedding specifically for POI data
-func (es *GeminiEmbeddingClient) GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error) {
-	if strings.TrimSpace(name) == "" {
-		return nil, fmt.Errorf("poi name cannot be empty")
+// POIEmbeddingText is the text GeneratePOIEmbedding embeds for a POI.
+func POIEmbeddingText(name, description, category string) string {
+	if description != "" {
+		return fmt.Sprintf("Name: %s\nCategory: %s\nDescription: %s", name, category, description)
 	}
+	return fmt.Sprintf("Name: %s\nCategory: %s", name, category)
+}
 
-	// Create a comprehensive text representation of the POI
-	var text string
+// CityEmbeddingText is the text GenerateCityEmbedding embeds for a city.
+func CityEmbeddingText(name, country, description string) string {
+	text := fmt.Sprintf("City: %s, Country: %s", name, country)
 	if description != "" {
-		text = fmt.Sprintf("Name: %s\nCategory: %s\nDescription: %s", name, category, description)
-	} else {
-		text = fmt.Sprintf("Name: %s\nCategory: %s", name, category)
+		text += fmt.Sprintf("\nDescription: %s", description)
+	}
+	return text
+}
+
+// GeneratePOIEmbedding embeds a POI as a retrieval document titled with its
+// name.
+func (es *GeminiEmbeddingClient) GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("poi name cannot be empty")
 	}
 
-	embedding, err := es.GenerateEmbedding(ctx, text, nil)
+	config := WithTaskType(nil, EmbeddingTaskRetrievalDocument, name)
+	embedding, err := es.GenerateEmbedding(ctx, POIEmbeddingText(name, description, category), config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate POI embedding: %w", err)
 	}
@@ -124,19 +187,15 @@ func (es *GeminiEmbeddingClient) GeneratePOIEmbedding(ctx context.Context, name,
 	return embedding, nil
 }
 
-// GenerateCityEmbedding generates an embedding specifically for city data
+// GenerateCityEmbedding embeds a city as a retrieval document titled with
+// its name.
 func (es *GeminiEmbeddingClient) GenerateCityEmbedding(ctx context.Context, name, country, description string) ([]float32, error) {
 	if strings.TrimSpace(name) == "" {
 		return nil, fmt.Errorf("city name cannot be empty")
 	}
 
-	// Create a comprehensive text representation of the city
-	text := fmt.Sprintf("City: %s, Country: %s", name, country)
-	if description != "" {
-		text += fmt.Sprintf("\nDescription: %s", description)
-	}
-
-	embedding, err := es.GenerateEmbedding(ctx, text, nil)
+	config := WithTaskType(nil, EmbeddingTaskRetrievalDocument, name)
+	embedding, err := es.GenerateEmbedding(ctx, CityEmbeddingText(name, country, description), config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate city embedding: %w", err)
 	}
@@ -144,25 +203,26 @@ func (es *GeminiEmbeddingClient) GenerateCityEmbedding(ctx context.Context, name
 	return embedding, nil
 }
 
-// GenerateUserPreferenceEmbedding generates an embedding for user preferences
-func (es *GeminiEmbeddingClient) GenerateUserPreferenceEmbedding(ctx context.Context, interests []string, preferences map[string]string) ([]float32, error) {
-	// Create a text representation of user preferences
-	text := "User Interests: "
-	for i, interest := range interests {
-		if i > 0 {
-			text += ", "
-		}
-		text += interest
-	}
-
+// UserPreferenceEmbeddingText is the text GenerateUserPreferenceEmbedding
+// embeds. Preferences are listed in key order so equal inputs give equal text.
+func UserPreferenceEmbeddingText(interests []string, preferences map[string]string) string {
+	var b strings.Builder
+	b.WriteString("User Interests: ")
+	b.WriteString(strings.Join(interests, ", "))
 	if len(preferences) > 0 {
-		text += "\nPreferences: "
-		for key, value := range preferences {
-			text += fmt.Sprintf("%s: %s; ", key, value)
+		b.WriteString("\nPreferences: ")
+		for _, key := range slices.Sorted(maps.Keys(preferences)) {
+			fmt.Fprintf(&b, "%s: %s; ", key, preferences[key])
 		}
 	}
+	return b.String()
+}
 
-	embedding, err := es.GenerateEmbedding(ctx, text, nil)
+// GenerateUserPreferenceEmbedding embeds user preferences for similarity
+// comparisons between users.
+func (es *GeminiEmbeddingClient) GenerateUserPreferenceEmbedding(ctx context.Context, interests []string, preferences map[string]string) ([]float32, error) {
+	text := UserPreferenceEmbeddingText(interests, preferences)
+	embedding, err := es.GenerateEmbedding(ctx, text, WithTaskType(nil, EmbeddingTaskSemanticSimilarity, ""))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate user preference embedding: %w", err)
 	}
@@ -170,9 +230,11 @@ func (es *GeminiEmbeddingClient) GenerateUserPreferenceEmbedding(ctx context.Con
 	return embedding, nil
 }
 
-// GenerateQueryEmbedding generates an embedding for search queries
+// GenerateQueryEmbedding embeds a search query for retrieving documents
+// embedded by GeneratePOIEmbedding and GenerateCityEmbedding.
 func (es *GeminiEmbeddingClient) GenerateQueryEmbedding(ctx context.Context, query string) ([]float32, error) {
-	embedding, err := es.GenerateEmbedding(ctx, query, nil)
+	ctx = WithPriority(ctx, priorityFrom(ctx, PriorityInteractive))
+	embedding, err := es.GenerateEmbedding(ctx, query, WithTaskType(nil, EmbeddingTaskRetrievalQuery, ""))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
@@ -180,27 +242,77 @@ func (es *GeminiEmbeddingClient) GenerateQueryEmbedding(ctx context.Context, que
 	return embedding, nil
 }
 
-// BatchGenerateEmbeddings generates embeddings for multiple texts at once
+// BatchGenerateEmbeddings embeds texts in as few requests as the batch limits
+// allow, concurrently and rate limited when WithWorkerPool is set, and
+// returns the embeddings in input order. A failed request is retried on its
+// own; if it still fails the embeddings of the other requests are returned
+// together with a *PartialEmbeddingError. Texts are embedded as
+// EmbeddingTaskRetrievalDocument.
 func (es *GeminiEmbeddingClient) BatchGenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	return es.BatchGenerateEmbeddingsWithConfig(ctx, texts, nil)
+}
+
+// BatchGenerateEmbeddingsWithConfig is BatchGenerateEmbeddings with config
+// applied to every text. An empty task type defaults to
+// EmbeddingTaskRetrievalDocument.
+func (es *GeminiEmbeddingClient) BatchGenerateEmbeddingsWithConfig(ctx context.Context, texts []string, config *genai.EmbedContentConfig) ([][]float32, error) {
 	if len(texts) == 0 {
 		return nil, fmt.Errorf("no texts provided for batch embedding")
 	}
-
-	embeddings := make([][]float32, len(texts))
-	var err error
-
-	// Generate embeddings sequentially
-	// TODO: Implement concurrent processing with rate limiting if needed
 	for i, text := range texts {
-		embeddings[i], err = es.GenerateEmbedding(ctx, text, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate embedding for text at index %d: %w", i, err)
+		if text == "" {
+			return nil, fmt.Errorf("text at index %d cannot be empty", i)
 		}
 	}
 
+	config = es.withDimension(embeddingConfig(config, EmbeddingTaskRetrievalDocument, ""))
+	embeddings, err := embedBatches(ctx, es.logger, texts, es.batchLimits, es.pool,
+		func(ctx context.Context, _ int, batch []string) ([][]float32, error) {
+			return es.embedBatch(ctx, batch, nil, config)
+		})
+	if err != nil {
+		return embeddings, err
+	}
+
 	es.logger.InfoContext(ctx, "Batch embeddings generated",
 		slog.Int("count", len(embeddings)),
 		slog.String("model", es.model))
 
 	return embeddings, nil
 }
+
+// BatchGenerateDocumentEmbeddings is BatchGenerateEmbeddings for retrieval
+// documents that each carry their own title, e.g. the name of the POI the
+// text describes. Empty titles are left out.
+func (es *GeminiEmbeddingClient) BatchGenerateDocumentEmbeddings(ctx context.Context, docs []EmbeddingInput) ([][]float32, error) {
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no documents provided for batch embedding")
+	}
+	texts := make([]string, len(docs))
+	titles := make([]string, len(docs))
+	for i, doc := range docs {
+		if doc.Text == "" {
+			return nil, fmt.Errorf("text at index %d cannot be empty", i)
+		}
+		texts[i], titles[i] = doc.Text, doc.Title
+	}
+
+	config := es.withDimension(WithTaskType(nil, EmbeddingTaskRetrievalDocument, ""))
+	embeddings, err := embedBatches(ctx, es.logger, texts, es.batchLimits, es.pool,
+		func(ctx context.Context, start int, batch []string) ([][]float32, error) {
+			batchTitles := titles[start : start+len(batch)]
+			if !slices.ContainsFunc(batchTitles, func(title string) bool { return title != "" }) {
+				batchTitles = nil
+			}
+			return es.embedBatch(ctx, batch, batchTitles, config)
+		})
+	if err != nil {
+		return embeddings, err
+	}
+
+	es.logger.InfoContext(ctx, "Batch document embeddings generated",
+		slog.Int("count", len(embeddings)),
+		slog.String("model", es.model))
+
+	return embeddings, nil
+}
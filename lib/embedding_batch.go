@@ -0,0 +1,250 @@
+package genai_sdk
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// EmbeddingBatchLimits bounds a single batched embedding request.
+type EmbeddingBatchLimits struct {
+	// MaxItems is the most texts sent in one request.
+	MaxItems int
+	// MaxTokens is the most estimated input tokens sent in one request. A
+	// single text above the limit is still sent, on its own.
+	MaxTokens int
+}
+
+// DefaultEmbeddingBatchLimits match the Gemini API limit of 100 contents per
+// request, with a conservative token ceiling.
+var DefaultEmbeddingBatchLimits = EmbeddingBatchLimits{MaxItems: 100, MaxTokens: 20_000}
+
+// EmbeddingBatchFailure reports one request of a batch that failed after its
+// retries. It covers texts[Start:End]. A multi-text request rejected as an
+// invalid argument is split in halves first, so such failures are usually a
+// single text; other errors fail the whole request.
+type EmbeddingBatchFailure struct {
+	Start, End int
+	Err        error
+}
+
+// PartialEmbeddingError is returned by BatchGenerateEmbeddings when some
+// requests failed. The embeddings of the texts in the other requests are
+// still returned; failed texts are nil.
+type PartialEmbeddingError struct {
+	Total    int
+	Failures []EmbeddingBatchFailure
+}
+
+func (e *PartialEmbeddingError) Error() string {
+	return fmt.Sprintf("failed to embed %d of %d texts in %d request(s): %v",
+		len(e.FailedIndices()), e.Total, len(e.Failures), e.Failures[0].Err)
+}
+
+func (e *PartialEmbeddingError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// FailedIndices returns the input positions that have no embedding.
+func (e *PartialEmbeddingError) FailedIndices() []int {
+	var idx []int
+	for _, f := range e.Failures {
+		for i := f.Start; i < f.End; i++ {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// WithBatchLimits overrides how BatchGenerateEmbeddings splits its input.
+// Zero fields keep the defaults.
+func (es *GeminiEmbeddingClient) WithBatchLimits(limits EmbeddingBatchLimits) *GeminiEmbeddingClient {
+	es.batchLimits = limits
+	return es
+}
+
+// WithRetryPolicy overrides the retry policy applied to each batched request.
+func (es *GeminiEmbeddingClient) WithRetryPolicy(policy RetryPolicy) *GeminiEmbeddingClient {
+	es.retryPolicy = policy
+	return es
+}
+
+// embedBatch embeds texts in a single request, retrying it as a whole.
+// titles, when not nil, gives each text its own title.
+func (es *GeminiEmbeddingClient) embedBatch(ctx context.Context, texts, titles []string, config *genai.EmbedContentConfig) ([][]float32, error) {
+	var checkTitles func() error
+	if titles != nil {
+		config, checkTitles = withTitles(config, titles)
+	}
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+	tokens := 0
+	for _, text := range texts {
+		tokens += estimateTokens(text)
+	}
+	resp, err := retryWithBackoff(ctx, es.retryPolicy, es.logger, "BatchGenerateEmbeddings",
+		func(ctx context.Context) (*genai.EmbedContentResponse, error) {
+			if err := es.waitRateLimit(ctx, tokens); err != nil {
+				return nil, err
+			}
+			return withBulkhead(ctx, es.bulkhead, PriorityBatch, func() (*genai.EmbedContentResponse, error) {
+				resp, err := es.client.Models.EmbedContent(ctx, es.model, contents, config)
+				if err == nil && checkTitles != nil {
+					err = checkTitles()
+				}
+				return resp, err
+			})
+		})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("received empty embedding response from API")
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Embeddings))
+	}
+	values := make([][]float32, len(texts))
+	for i, emb := range resp.Embeddings {
+		if emb == nil || len(emb.Values) == 0 {
+			return nil, fmt.Errorf("received empty embedding values for text %d of the request", i)
+		}
+		if values[i], err = es.fitDimension(emb.Values, config); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// embedBatches embeds texts in requests bounded by limits, running up to
+// pool.Concurrency requests at once, and maps the results back to input
+// order. A failed request does not stop the others. When one with several
+// texts is rejected as an invalid argument, its halves are retried separately
+// so one bad text doesn't fail its neighbours; remaining failures are
+// reported as a *PartialEmbeddingError.
+func embedBatches(ctx context.Context, logger *slog.Logger, texts []string, limits EmbeddingBatchLimits, pool EmbeddingPoolOptions,
+	embed func(ctx context.Context, start int, texts []string) ([][]float32, error),
+) ([][]float32, error) {
+	ranges := embeddingBatchRanges(texts, limits)
+	work := make(chan indexRange)
+	go func() {
+		defer close(work)
+		for _, r := range ranges {
+			select {
+			case work <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	embeddings := make([][]float32, len(texts))
+	partial := &PartialEmbeddingError{Total: len(texts)}
+	progress := EmbeddingProgress{Total: len(texts)}
+	var mu sync.Mutex
+	var run func(r indexRange)
+	run = func(r indexRange) {
+		values, err := embed(ctx, r.start, texts[r.start:r.end])
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		if err != nil && r.end-r.start > 1 && splittableEmbeddingError(err) {
+			logger.DebugContext(ctx, "Embedding batch failed, splitting",
+				slog.Int("start", r.start),
+				slog.Int("end", r.end),
+				slog.Any("error", err))
+			mid := r.start + (r.end-r.start)/2
+			run(indexRange{r.start, mid})
+			run(indexRange{mid, r.end})
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			logger.WarnContext(ctx, "Embedding batch failed",
+				slog.Int("start", r.start),
+				slog.Int("end", r.end),
+				slog.Any("error", err))
+			partial.Failures = append(partial.Failures, EmbeddingBatchFailure{Start: r.start, End: r.end, Err: err})
+			progress.Failed += r.end - r.start
+		} else {
+			copy(embeddings[r.start:r.end], values)
+			progress.Done += r.end - r.start
+		}
+		if pool.OnProgress != nil {
+			pool.OnProgress(progress)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for range min(max(pool.Concurrency, 1), len(ranges)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range work {
+				run(r)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(partial.Failures) > 0 {
+		slices.SortFunc(partial.Failures, func(a, b EmbeddingBatchFailure) int { return a.Start - b.Start })
+		return embeddings, partial
+	}
+	return embeddings, nil
+}
+
+// splittableEmbeddingError reports whether a failed request might succeed in
+// smaller parts. Only an invalid argument can come from one bad text; other
+// errors have already used up their retries and would only be multiplied.
+func splittableEmbeddingError(err error) bool {
+	return errors.Is(ClassifyError(err), ErrInvalidArgument)
+}
+
+type indexRange struct{ start, end int }
+
+// embeddingBatchRanges splits texts into consecutive ranges that respect
+// limits.
+func embeddingBatchRanges(texts []string, limits EmbeddingBatchLimits) []indexRange {
+	maxItems := cmp.Or(limits.MaxItems, DefaultEmbeddingBatchLimits.MaxItems)
+	maxTokens := cmp.Or(limits.MaxTokens, DefaultEmbeddingBatchLimits.MaxTokens)
+
+	var ranges []indexRange
+	start, tokens := 0, 0
+	for i, text := range texts {
+		n := estimateTokens(text)
+		if i > start && (i-start == maxItems || tokens+n > maxTokens) {
+			ranges = append(ranges, indexRange{start, i})
+			start, tokens = i, 0
+		}
+		tokens += n
+	}
+	if start < len(texts) {
+		ranges = append(ranges, indexRange{start, len(texts)})
+	}
+	return ranges
+}
+
+// estimateTokens approximates the token count of text at four bytes per
+// token, which overestimates for typical English.
+func estimateTokens(text string) int {
+	return max(1, (len(strings.TrimSpace(text))+3)/4)
+}
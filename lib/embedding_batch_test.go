@@ -0,0 +1,194 @@
+package genai_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestEmbeddingBatchRanges(t *testing.T) {
+	long := strings.Repeat("x", 400) // ~100 tokens
+	tests := []struct {
+		name   string
+		texts  []string
+		limits EmbeddingBatchLimits
+		want   []indexRange
+	}{
+		{"single batch", []string{"a", "b", "c"}, EmbeddingBatchLimits{MaxItems: 10, MaxTokens: 100}, []indexRange{{0, 3}}},
+		{"item limit", []string{"a", "b", "c", "d", "e"}, EmbeddingBatchLimits{MaxItems: 2, MaxTokens: 100}, []indexRange{{0, 2}, {2, 4}, {4, 5}}},
+		{"token limit", []string{long, "a", long, long}, EmbeddingBatchLimits{MaxItems: 10, MaxTokens: 150}, []indexRange{{0, 2}, {2, 3}, {3, 4}}},
+		{"oversized text alone", []string{"a", long, "b"}, EmbeddingBatchLimits{MaxItems: 10, MaxTokens: 50}, []indexRange{{0, 1}, {1, 2}, {2, 3}}},
+		{"zero limits use defaults", make([]string, 250), EmbeddingBatchLimits{}, []indexRange{{0, 100}, {100, 200}, {200, 250}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := embeddingBatchRanges(tt.texts, tt.limits); !slices.Equal(got, tt.want) {
+				t.Errorf("ranges = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbedBatches_PartialFailure(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	texts := []string{"a", "b", "c", "d", "e"}
+	embed := func(_ context.Context, _ int, batch []string) ([][]float32, error) {
+		if slices.Contains(batch, "c") {
+			return nil, &Error{Kind: ErrInvalidArgument, Code: 400, Err: errors.New("bad text")}
+		}
+		values := make([][]float32, len(batch))
+		for i, text := range batch {
+			values[i] = []float32{float32(text[0])}
+		}
+		return values, nil
+	}
+
+	got, err := embedBatches(context.Background(), logger, texts, EmbeddingBatchLimits{MaxItems: 2}, EmbeddingPoolOptions{}, embed)
+	var partial *PartialEmbeddingError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected *PartialEmbeddingError, got %v", err)
+	}
+	// The failed {c, d} request is split, so only c is lost.
+	if !slices.Equal(partial.FailedIndices(), []int{2}) || partial.Total != 5 {
+		t.Errorf("failed indices = %v, total = %d", partial.FailedIndices(), partial.Total)
+	}
+	if got[0][0] != 'a' || got[1][0] != 'b' || got[2] != nil || got[3][0] != 'd' || got[4][0] != 'e' {
+		t.Errorf("embeddings = %v", got)
+	}
+}
+
+func TestEmbedBatches_SplitsOnlyInvalidArguments(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, want := range []error{
+		&Error{Kind: ErrPermissionDenied, Code: 403, Err: errors.New("bad key")},
+		&Error{Kind: ErrServerError, Code: 503, Err: errors.New("unavailable")},
+		&Error{Kind: ErrRateLimited, Code: 429, Err: errors.New("slow down")},
+		errors.New("connection reset"),
+	} {
+		calls := 0
+		embed := func(context.Context, int, []string) ([][]float32, error) {
+			calls++
+			return nil, want
+		}
+		_, err := embedBatches(context.Background(), logger, []string{"a", "b", "c", "d"}, EmbeddingBatchLimits{}, EmbeddingPoolOptions{}, embed)
+		var partial *PartialEmbeddingError
+		if !errors.Is(err, want) || calls != 1 || !errors.As(err, &partial) || len(partial.FailedIndices()) != 4 {
+			t.Errorf("%v: err = %v after %d calls, want the whole batch failed by one call", want, err, calls)
+		}
+	}
+}
+
+// testEmbedRequest is one content of a batchEmbedContents call.
+type testEmbedRequest struct {
+	Content              genai.Content `json:"content"`
+	TaskType             string        `json:"taskType"`
+	Title                string        `json:"title"`
+	OutputDimensionality *int32        `json:"outputDimensionality"`
+}
+
+// newTestEmbeddingServer serves batchEmbedContents, failing the first attempt
+// of any request that contains failText. It records the texts of each call
+// and every content sent.
+func newTestEmbeddingServer(t *testing.T, failText string) (*GeminiEmbeddingClient, *[][]string, *[]testEmbedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests [][]string
+	var contents []testEmbedRequest
+	failed := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ":batchEmbedContents") {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Requests []testEmbedRequest `json:"requests"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var texts []string
+		for _, req := range body.Requests {
+			texts = append(texts, req.Content.Parts[0].Text)
+		}
+		mu.Lock()
+		requests = append(requests, texts)
+		contents = append(contents, body.Requests...)
+		fail := slices.Contains(texts, failText) && !failed
+		failed = failed || fail
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":{"code":503,"message":"overloaded","status":"UNAVAILABLE"}}`)
+			return
+		}
+		var resp struct {
+			Embeddings []genai.ContentEmbedding `json:"embeddings"`
+		}
+		for _, text := range texts {
+			resp.Embeddings = append(resp.Embeddings, genai.ContentEmbedding{Values: []float32{float32(text[0]), 1}})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-api-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	es := &GeminiEmbeddingClient{
+		client: client,
+		model:  EmbeddingModel,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		retryPolicy: RetryPolicy{
+			MaxRetries: 2,
+			BaseDelay:  time.Millisecond,
+			MaxDelay:   time.Millisecond,
+		},
+	}
+	return es, &requests, &contents
+}
+
+func TestBatchGenerateEmbeddings_RetriesOnlyFailedBatch(t *testing.T) {
+	es, requests, _ := newTestEmbeddingServer(t, "c")
+	es.WithBatchLimits(EmbeddingBatchLimits{MaxItems: 2})
+
+	got, err := es.BatchGenerateEmbeddings(context.Background(), []string{"a", "b", "c", "d", "e"})
+	if err != nil {
+		t.Fatalf("BatchGenerateEmbeddings: %v", err)
+	}
+	want := [][]string{{"a", "b"}, {"c", "d"}, {"c", "d"}, {"e"}}
+	if !slices.EqualFunc(*requests, want, slices.Equal) {
+		t.Errorf("requests = %v, want %v", *requests, want)
+	}
+	for i, text := range []string{"a", "b", "c", "d", "e"} {
+		if len(got[i]) != 2 || got[i][0] != float32(text[0]) {
+			t.Errorf("embedding %d = %v, want one for %q", i, got[i], text)
+		}
+	}
+}
+
+func TestBatchGenerateEmbeddings_RejectsEmptyText(t *testing.T) {
+	es, requests, _ := newTestEmbeddingServer(t, "")
+	if _, err := es.BatchGenerateEmbeddings(context.Background(), []string{"a", ""}); err == nil {
+		t.Error("expected error for empty text")
+	}
+	if len(*requests) != 0 {
+		t.Errorf("sent %d requests, want none", len(*requests))
+	}
+}
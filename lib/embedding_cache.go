@@ -0,0 +1,453 @@
+package genai_sdk
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/genai"
+)
+
+// DefaultEmbeddingCacheSize is the number of vectors kept in memory by
+// NewCachedEmbeddingClient.
+const DefaultEmbeddingCacheSize = 10_000
+
+// EmbeddingStore persists embedding vectors by cache key.
+type EmbeddingStore interface {
+	Get(ctx context.Context, key string) ([]float32, bool, error)
+	Put(ctx context.Context, key string, vec []float32) error
+}
+
+// EmbeddingCacheStats counts cache lookups since the client was created.
+type EmbeddingCacheStats struct {
+	// Hits were served from memory, StoreHits from the persistent store.
+	Hits      uint64
+	StoreHits uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// CachedEmbeddingClient is an EmbeddingClient that serves repeated texts
+// from an LRU in memory, backed by an optional persistent store, and only
+// sends the rest to the wrapped client. Entries are keyed by model, task
+// type, dimensionality, and a hash of the title and the whitespace-normalized
+// text. Concurrent single-text calls for the same key share one request.
+type CachedEmbeddingClient struct {
+	next   EmbeddingClient
+	lru    *embeddingLRU
+	store  EmbeddingStore
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	inflight map[string]*embeddingCall
+
+	hits, storeHits, misses atomic.Uint64
+}
+
+// embeddingCall is an in-flight request for one cache key; vec and err are
+// set before done is closed.
+type embeddingCall struct {
+	done chan struct{}
+	vec  []float32
+	err  error
+}
+
+var _ EmbeddingClient = (*CachedEmbeddingClient)(nil)
+
+// NewCachedEmbeddingClient wraps next with a cache of up to size vectors in
+// memory; size defaults to DefaultEmbeddingCacheSize. store may be nil. The
+// model and dimensionality of a *GeminiEmbeddingClient are part of the key,
+// read on every call so later WithDimension changes are honoured.
+func NewCachedEmbeddingClient(next EmbeddingClient, size int, store EmbeddingStore) *CachedEmbeddingClient {
+	if size <= 0 {
+		size = DefaultEmbeddingCacheSize
+	}
+	return &CachedEmbeddingClient{
+		next:     next,
+		lru:      newEmbeddingLRU(size),
+		store:    store,
+		logger:   slog.Default(),
+		inflight: make(map[string]*embeddingCall),
+	}
+}
+
+// WithLogger sets the logger used for store diagnostics.
+func (c *CachedEmbeddingClient) WithLogger(logger *slog.Logger) *CachedEmbeddingClient {
+	if logger != nil {
+		c.logger = logger
+	}
+	return c
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *CachedEmbeddingClient) Stats() EmbeddingCacheStats {
+	return EmbeddingCacheStats{
+		Hits:      c.hits.Load(),
+		StoreHits: c.storeHits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.lru.evictions.Load(),
+	}
+}
+
+func (c *CachedEmbeddingClient) Close() {
+	c.next.Close()
+}
+
+func (c *CachedEmbeddingClient) GenerateEmbedding(ctx context.Context, text string, config *genai.EmbedContentConfig) ([]float32, error) {
+	key := c.key(text, embeddingConfig(config, "", ""))
+	return c.cached(ctx, key, func() ([]float32, error) { return c.next.GenerateEmbedding(ctx, text, config) })
+}
+
+func (c *CachedEmbeddingClient) GenerateQueryEmbedding(ctx context.Context, query string) ([]float32, error) {
+	key := c.key(query, WithTaskType(nil, EmbeddingTaskRetrievalQuery, ""))
+	return c.cached(ctx, key, func() ([]float32, error) { return c.next.GenerateQueryEmbedding(ctx, query) })
+}
+
+func (c *CachedEmbeddingClient) GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error) {
+	key := c.key(POIEmbeddingText(name, description, category), WithTaskType(nil, EmbeddingTaskRetrievalDocument, name))
+	return c.cached(ctx, key, func() ([]float32, error) { return c.next.GeneratePOIEmbedding(ctx, name, description, category) })
+}
+
+func (c *CachedEmbeddingClient) GenerateCityEmbedding(ctx context.Context, name, country, description string) ([]float32, error) {
+	key := c.key(CityEmbeddingText(name, country, description), WithTaskType(nil, EmbeddingTaskRetrievalDocument, name))
+	return c.cached(ctx, key, func() ([]float32, error) { return c.next.GenerateCityEmbedding(ctx, name, country, description) })
+}
+
+func (c *CachedEmbeddingClient) GenerateUserPreferenceEmbedding(ctx context.Context, interests []string, preferences map[string]string) ([]float32, error) {
+	key := c.key(UserPreferenceEmbeddingText(interests, preferences), WithTaskType(nil, EmbeddingTaskSemanticSimilarity, ""))
+	return c.cached(ctx, key, func() ([]float32, error) {
+		return c.next.GenerateUserPreferenceEmbedding(ctx, interests, preferences)
+	})
+}
+
+func (c *CachedEmbeddingClient) BatchGenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	return c.BatchGenerateEmbeddingsWithConfig(ctx, texts, nil)
+}
+
+// BatchGenerateEmbeddingsWithConfig looks every text up first and sends only
+// the missing ones, each distinct text once, to the wrapped client. On a
+// partial failure the *PartialEmbeddingError refers to positions in texts.
+func (c *CachedEmbeddingClient) BatchGenerateEmbeddingsWithConfig(ctx context.Context, texts []string, config *genai.EmbedContentConfig) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts provided for batch embedding")
+	}
+	effective := embeddingConfig(config, EmbeddingTaskRetrievalDocument, "")
+	keys := make([]string, len(texts))
+	for i, text := range texts {
+		keys[i] = c.key(text, effective)
+	}
+	return c.cachedBatch(ctx, keys, func(missing []int) ([][]float32, error) {
+		return c.next.BatchGenerateEmbeddingsWithConfig(ctx, pick(texts, missing), config)
+	})
+}
+
+// BatchGenerateDocumentEmbeddings caches like BatchGenerateEmbeddingsWithConfig,
+// keying each document by its own title.
+func (c *CachedEmbeddingClient) BatchGenerateDocumentEmbeddings(ctx context.Context, docs []EmbeddingInput) ([][]float32, error) {
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no documents provided for batch embedding")
+	}
+	keys := make([]string, len(docs))
+	for i, doc := range docs {
+		keys[i] = c.key(doc.Text, WithTaskType(nil, EmbeddingTaskRetrievalDocument, doc.Title))
+	}
+	return c.cachedBatch(ctx, keys, func(missing []int) ([][]float32, error) {
+		return c.next.BatchGenerateDocumentEmbeddings(ctx, pick(docs, missing))
+	})
+}
+
+// cachedBatch serves the inputs whose keys are cached and calls embed with
+// the positions of the rest, one per distinct key. embed returns vectors in
+// the order of those positions.
+func (c *CachedEmbeddingClient) cachedBatch(ctx context.Context, keys []string, embed func(missing []int) ([][]float32, error)) ([][]float32, error) {
+	embeddings := make([][]float32, len(keys))
+	positions := make(map[string][]int) // key -> positions in the input
+	var missing []int
+	for i, key := range keys {
+		if vec, ok := c.lookup(ctx, key); ok {
+			embeddings[i] = vec
+			continue
+		}
+		if _, seen := positions[key]; !seen {
+			missing = append(missing, i)
+		}
+		positions[key] = append(positions[key], i)
+	}
+	c.misses.Add(uint64(len(missing)))
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	vecs, err := embed(missing)
+	var partial *PartialEmbeddingError
+	if err != nil && !errors.As(err, &partial) {
+		return nil, err
+	}
+	for j, vec := range vecs {
+		if vec == nil {
+			continue
+		}
+		key := keys[missing[j]]
+		c.put(ctx, key, vec)
+		for _, i := range positions[key] {
+			embeddings[i] = slices.Clone(vec)
+		}
+	}
+	if partial == nil {
+		return embeddings, nil
+	}
+
+	remapped := &PartialEmbeddingError{Total: len(keys)}
+	for _, f := range partial.Failures {
+		for j := f.Start; j < f.End; j++ {
+			for _, i := range positions[keys[missing[j]]] {
+				remapped.Failures = append(remapped.Failures, EmbeddingBatchFailure{Start: i, End: i + 1, Err: f.Err})
+			}
+		}
+	}
+	slices.SortFunc(remapped.Failures, func(a, b EmbeddingBatchFailure) int { return a.Start - b.Start })
+	return embeddings, remapped
+}
+
+// pick returns the elements of s at idx.
+func pick[T any](s []T, idx []int) []T {
+	out := make([]T, len(idx))
+	for j, i := range idx {
+		out[j] = s[i]
+	}
+	return out
+}
+
+// cached returns the vector for key, calling embed and storing its result on
+// a miss. Callers that miss while another call for key is in flight wait for
+// its result instead of sending their own request.
+func (c *CachedEmbeddingClient) cached(ctx context.Context, key string, embed func() ([]float32, error)) ([]float32, error) {
+	for {
+		if vec, ok := c.lookup(ctx, key); ok {
+			return vec, nil
+		}
+		c.mu.Lock()
+		call, ok := c.inflight[key]
+		if !ok {
+			call = &embeddingCall{done: make(chan struct{})}
+			c.inflight[key] = call
+			c.mu.Unlock()
+			return c.embedOnce(ctx, key, call, embed)
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-call.done:
+		}
+		// The caller that made the request may have been cancelled; try
+		// again rather than returning its context error.
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			continue
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		c.hits.Add(1)
+		return slices.Clone(call.vec), nil
+	}
+}
+
+// embedOnce runs embed for the in-flight call registered under key and
+// publishes its result to waiting callers.
+func (c *CachedEmbeddingClient) embedOnce(ctx context.Context, key string, call *embeddingCall, embed func() ([]float32, error)) ([]float32, error) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+	c.misses.Add(1)
+	call.vec, call.err = embed()
+	if call.err != nil {
+		return nil, call.err
+	}
+	c.put(ctx, key, call.vec)
+	return slices.Clone(call.vec), nil
+}
+
+// lookup returns a copy of the cached vector for key, promoting store hits
+// into memory.
+func (c *CachedEmbeddingClient) lookup(ctx context.Context, key string) ([]float32, bool) {
+	if vec, ok := c.lru.get(key); ok {
+		c.hits.Add(1)
+		return slices.Clone(vec), true
+	}
+	if c.store == nil {
+		return nil, false
+	}
+	vec, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		c.logger.WarnContext(ctx, "failed to read embedding store",
+			slog.String("key", key),
+			slog.Any("error", err))
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	c.storeHits.Add(1)
+	c.lru.add(key, vec)
+	return slices.Clone(vec), true
+}
+
+func (c *CachedEmbeddingClient) put(ctx context.Context, key string, vec []float32) {
+	vec = slices.Clone(vec)
+	c.lru.add(key, vec)
+	if c.store != nil {
+		if err := c.store.Put(ctx, key, vec); err != nil {
+			c.logger.WarnContext(ctx, "failed to write embedding store",
+				slog.String("key", key),
+				slog.Any("error", err))
+		}
+	}
+}
+
+// key builds the cache key for text embedded with cfg.
+func (c *CachedEmbeddingClient) key(text string, cfg *genai.EmbedContentConfig) string {
+	var model string
+	var dim int
+	if info, ok := c.next.(interface {
+		Model() string
+		Dimension() int
+	}); ok {
+		model, dim = info.Model(), info.Dimension()
+	}
+	if cfg.OutputDimensionality != nil {
+		dim = int(*cfg.OutputDimensionality)
+	}
+	h := sha256.New()
+	h.Write([]byte(cfg.Title))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(strings.Fields(text), " ")))
+	return fmt.Sprintf("%s:%s:%d:%s", model, cfg.TaskType, dim, hex.EncodeToString(h.Sum(nil)))
+}
+
+// embeddingLRU is a fixed-size, least-recently-used map of vectors.
+type embeddingLRU struct {
+	mu        sync.Mutex
+	size      int
+	order     *list.List // front is most recently used
+	entries   map[string]*list.Element
+	evictions atomic.Uint64
+}
+
+type lruEntry struct {
+	key string
+	vec []float32
+}
+
+func newEmbeddingLRU(size int) *embeddingLRU {
+	return &embeddingLRU{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (l *embeddingLRU) get(key string) ([]float32, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return el.Value.(*lruEntry).vec, true
+}
+
+func (l *embeddingLRU) add(key string, vec []float32) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.entries[key]; ok {
+		el.Value.(*lruEntry).vec = vec
+		l.order.MoveToFront(el)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, vec: vec})
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+		l.evictions.Add(1)
+	}
+}
+
+// DiskEmbeddingStore is an EmbeddingStore keeping one little-endian float32
+// file per vector under a directory, so the cache survives restarts.
+type DiskEmbeddingStore struct {
+	dir string
+}
+
+// NewDiskEmbeddingStore creates a store in dir, creating it if needed.
+func NewDiskEmbeddingStore(dir string) (*DiskEmbeddingStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create embedding store: %w", err)
+	}
+	return &DiskEmbeddingStore{dir: dir}, nil
+}
+
+func (s *DiskEmbeddingStore) Get(_ context.Context, key string) ([]float32, bool, error) {
+	data, err := os.ReadFile(s.path(key))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	case len(data)%4 != 0:
+		return nil, false, fmt.Errorf("corrupt embedding file for %q", key)
+	}
+	vec := make([]float32, len(data)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vec, true, nil
+}
+
+func (s *DiskEmbeddingStore) Put(_ context.Context, key string, vec []float32) error {
+	data := make([]byte, 0, 4*len(vec))
+	for _, x := range vec {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(x))
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to write embedding: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write embedding: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write embedding: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write embedding: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write embedding: %w", err)
+	}
+	return nil
+}
+
+// path spreads files over 256 subdirectories by key hash.
+func (s *DiskEmbeddingStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(s.dir, name[:2], name)
+}
@@ -0,0 +1,271 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// countingEmbeddingClient embeds each text as {len(text), first byte} and
+// records the texts it was asked for.
+type countingEmbeddingClient struct {
+	calls   int
+	batches [][]string
+	fail    string
+}
+
+func (f *countingEmbeddingClient) vector(text string) []float32 {
+	return []float32{float32(len(text)), float32(text[0])}
+}
+
+func (f *countingEmbeddingClient) GenerateEmbedding(_ context.Context, text string, _ *genai.EmbedContentConfig) ([]float32, error) {
+	f.calls++
+	return f.vector(text), nil
+}
+
+func (f *countingEmbeddingClient) GenerateQueryEmbedding(ctx context.Context, query string) ([]float32, error) {
+	return f.GenerateEmbedding(ctx, query, nil)
+}
+
+func (f *countingEmbeddingClient) GeneratePOIEmbedding(ctx context.Context, name, description, category string) ([]float32, error) {
+	return f.GenerateEmbedding(ctx, POIEmbeddingText(name, description, category), nil)
+}
+
+func (f *countingEmbeddingClient) GenerateCityEmbedding(ctx context.Context, name, country, description string) ([]float32, error) {
+	return f.GenerateEmbedding(ctx, CityEmbeddingText(name, country, description), nil)
+}
+
+func (f *countingEmbeddingClient) GenerateUserPreferenceEmbedding(ctx context.Context, interests []string, preferences map[string]string) ([]float32, error) {
+	return f.GenerateEmbedding(ctx, UserPreferenceEmbeddingText(interests, preferences), nil)
+}
+
+func (f *countingEmbeddingClient) BatchGenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	return f.BatchGenerateEmbeddingsWithConfig(ctx, texts, nil)
+}
+
+func (f *countingEmbeddingClient) BatchGenerateEmbeddingsWithConfig(_ context.Context, texts []string, _ *genai.EmbedContentConfig) ([][]float32, error) {
+	f.calls++
+	f.batches = append(f.batches, texts)
+	out := make([][]float32, len(texts))
+	partial := &PartialEmbeddingError{Total: len(texts)}
+	for i, text := range texts {
+		if text == f.fail {
+			partial.Failures = append(partial.Failures, EmbeddingBatchFailure{Start: i, End: i + 1, Err: errors.New("boom")})
+			continue
+		}
+		out[i] = f.vector(text)
+	}
+	if len(partial.Failures) > 0 {
+		return out, partial
+	}
+	return out, nil
+}
+
+func (f *countingEmbeddingClient) BatchGenerateDocumentEmbeddings(ctx context.Context, docs []EmbeddingInput) ([][]float32, error) {
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+	return f.BatchGenerateEmbeddingsWithConfig(ctx, texts, nil)
+}
+
+func (f *countingEmbeddingClient) Close() {}
+
+func TestCachedEmbeddingClient_Single(t *testing.T) {
+	next := &countingEmbeddingClient{}
+	c := NewCachedEmbeddingClient(next, 0, nil)
+	ctx := context.Background()
+
+	first, _ := c.GenerateQueryEmbedding(ctx, "cafés near Rossio")
+	second, _ := c.GenerateQueryEmbedding(ctx, "  cafés   near\nRossio ")
+	if next.calls != 1 || !slices.Equal(first, second) {
+		t.Errorf("calls = %d; normalized text should hit the cache", next.calls)
+	}
+
+	// Same text with another task type is a different embedding.
+	_, _ = c.GenerateEmbedding(ctx, "cafés near Rossio", nil)
+	_, _ = c.GenerateUserPreferenceEmbedding(ctx, []string{"art"}, map[string]string{"b": "2", "a": "1"})
+	_, _ = c.GenerateUserPreferenceEmbedding(ctx, []string{"art"}, map[string]string{"a": "1", "b": "2"})
+	if next.calls != 3 {
+		t.Errorf("calls = %d, want 3", next.calls)
+	}
+
+	first[0] = -1 // callers own their copy
+	if again, _ := c.GenerateQueryEmbedding(ctx, "cafés near Rossio"); again[0] == -1 {
+		t.Error("cached vector was modified through a returned slice")
+	}
+
+	if got := c.Stats(); got != (EmbeddingCacheStats{Hits: 3, Misses: 3}) {
+		t.Errorf("stats = %+v", got)
+	}
+}
+
+func TestCachedEmbeddingClient_BatchSendsOnlyMisses(t *testing.T) {
+	next := &countingEmbeddingClient{}
+	c := NewCachedEmbeddingClient(next, 0, nil)
+	ctx := context.Background()
+
+	if _, err := c.BatchGenerateEmbeddings(ctx, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.BatchGenerateEmbeddings(ctx, []string{"bb", "a", "ccc", "bb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(next.batches[1], []string{"bb", "ccc"}) {
+		t.Errorf("second batch sent %v, want [bb ccc]", next.batches[1])
+	}
+	for i, want := range []float32{2, 1, 3, 2} {
+		if got[i][0] != want {
+			t.Errorf("embedding %d = %v", i, got[i])
+		}
+	}
+
+	if _, err := c.BatchGenerateEmbeddings(ctx, []string{"ccc", "a"}); err != nil || next.calls != 2 {
+		t.Errorf("fully cached batch: calls = %d, err = %v", next.calls, err)
+	}
+}
+
+func TestCachedEmbeddingClient_DocumentTitlesAreKeyed(t *testing.T) {
+	next := &countingEmbeddingClient{}
+	c := NewCachedEmbeddingClient(next, 0, nil)
+	ctx := context.Background()
+
+	if _, err := c.BatchGenerateDocumentEmbeddings(ctx, []EmbeddingInput{{Text: "fort", Title: "Belém Tower"}}); err != nil {
+		t.Fatal(err)
+	}
+	docs := []EmbeddingInput{{Text: "fort", Title: "Belém Tower"}, {Text: "fort", Title: "São Jorge Castle"}}
+	if _, err := c.BatchGenerateDocumentEmbeddings(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+	if next.calls != 2 || len(next.batches[1]) != 1 {
+		t.Errorf("batches = %v; only the new title should be sent", next.batches)
+	}
+}
+
+func TestCachedEmbeddingClient_BatchPartialFailure(t *testing.T) {
+	next := &countingEmbeddingClient{fail: "bad"}
+	c := NewCachedEmbeddingClient(next, 0, nil)
+	ctx := context.Background()
+	_, _ = c.BatchGenerateEmbeddings(ctx, []string{"a"})
+
+	got, err := c.BatchGenerateEmbeddings(ctx, []string{"a", "bad", "bb", "bad"})
+	var partial *PartialEmbeddingError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected *PartialEmbeddingError, got %v", err)
+	}
+	if !slices.Equal(partial.FailedIndices(), []int{1, 3}) || partial.Total != 4 {
+		t.Errorf("failed indices = %v, total %d", partial.FailedIndices(), partial.Total)
+	}
+	if got[0] == nil || got[1] != nil || got[2] == nil || got[3] != nil {
+		t.Errorf("embeddings = %v", got)
+	}
+
+	// The failure is not cached.
+	_, _ = c.BatchGenerateEmbeddings(ctx, []string{"bad"})
+	if last := next.batches[len(next.batches)-1]; !slices.Equal(last, []string{"bad"}) {
+		t.Errorf("last batch = %v", last)
+	}
+}
+
+func TestCachedEmbeddingClient_LRUEviction(t *testing.T) {
+	next := &countingEmbeddingClient{}
+	c := NewCachedEmbeddingClient(next, 2, nil)
+	ctx := context.Background()
+
+	for _, text := range []string{"a", "b", "a", "c", "a", "b"} {
+		_, _ = c.GenerateEmbedding(ctx, text, nil)
+	}
+	// "b" was least recently used when "c" arrived.
+	if next.calls != 4 || c.Stats().Evictions != 2 {
+		t.Errorf("calls = %d, stats = %+v", next.calls, c.Stats())
+	}
+}
+
+func TestCachedEmbeddingClient_DiskStore(t *testing.T) {
+	store, err := NewDiskEmbeddingStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	next := &countingEmbeddingClient{}
+	want, _ := NewCachedEmbeddingClient(next, 0, store).GeneratePOIEmbedding(ctx, "Belém Tower", "", "landmark")
+
+	// A fresh client, as after a restart, reads the vector back from disk.
+	restarted := NewCachedEmbeddingClient(next, 0, store)
+	got, err := restarted.GeneratePOIEmbedding(ctx, "Belém Tower", "", "landmark")
+	if err != nil || !slices.Equal(got, want) || next.calls != 1 {
+		t.Errorf("got %v, %v after %d calls; want %v from disk", got, err, next.calls, want)
+	}
+	if stats := restarted.Stats(); stats.StoreHits != 1 || stats.Misses != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCachedEmbeddingClient_KeyIncludesModelAndDimension(t *testing.T) {
+	es := &GeminiEmbeddingClient{model: EmbeddingModel, dimension: 256}
+	c := NewCachedEmbeddingClient(es, 0, nil)
+	cfg := WithTaskType(nil, EmbeddingTaskRetrievalQuery, "")
+	key := c.key("hello", cfg)
+	if want := EmbeddingModel + ":" + EmbeddingTaskRetrievalQuery + ":256:"; key[:len(want)] != want {
+		t.Errorf("key = %q, want prefix %q", key, want)
+	}
+	cfg.OutputDimensionality = genai.Ptr[int32](128)
+	if c.key("hello", cfg) == key {
+		t.Error("per-call dimensionality should change the key")
+	}
+
+	cfg.OutputDimensionality = nil
+	es.WithDimension(512)
+	if c.key("hello", cfg) == key {
+		t.Error("changing the wrapped client's dimension should change the key")
+	}
+}
+
+// blockingEmbeddingClient holds query embeddings until release is closed.
+type blockingEmbeddingClient struct {
+	countingEmbeddingClient
+	release chan struct{}
+	queries atomic.Int32
+}
+
+func (f *blockingEmbeddingClient) GenerateQueryEmbedding(_ context.Context, query string) ([]float32, error) {
+	f.queries.Add(1)
+	<-f.release
+	return f.vector(query), nil
+}
+
+func TestCachedEmbeddingClient_ConcurrentMissesShareOneCall(t *testing.T) {
+	next := &blockingEmbeddingClient{release: make(chan struct{})}
+	c := NewCachedEmbeddingClient(next, 0, nil)
+
+	var wg sync.WaitGroup
+	results := make([][]float32, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.GenerateQueryEmbedding(context.Background(), "museums")
+		}()
+	}
+	waitFor(t, func() bool { return next.queries.Load() == 1 })
+	time.Sleep(10 * time.Millisecond) // let the others find the call in flight
+	close(next.release)
+	wg.Wait()
+
+	if n := next.queries.Load(); n != 1 {
+		t.Errorf("wrapped client called %d times, want 1", n)
+	}
+	for i, vec := range results {
+		if len(vec) != 2 {
+			t.Errorf("result %d = %v", i, vec)
+		}
+	}
+}
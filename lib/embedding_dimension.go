@@ -0,0 +1,98 @@
+package genai_sdk
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// EmbeddingDimensionError reports a vector whose length does not match the
+// configured dimensionality.
+type EmbeddingDimensionError struct {
+	Model string
+	Want  int
+	Got   int
+}
+
+func (e *EmbeddingDimensionError) Error() string {
+	return fmt.Sprintf("embedding model %s returned %d dimensions, want %d", e.Model, e.Got, e.Want)
+}
+
+// WithDimension sets the dimensionality of returned vectors, which is sent as
+// OutputDimensionality and checked on every response. Matryoshka models
+// (Gemini embedding, text-embedding-004 and later) return vectors truncated
+// to n and renormalized to unit length. Zero accepts whatever the model
+// returns. Defaults to EmbeddingDimension.
+func (es *GeminiEmbeddingClient) WithDimension(n int) *GeminiEmbeddingClient {
+	if n >= 0 {
+		es.dimension = n
+	}
+	return es
+}
+
+// withDimension sets OutputDimensionality on a copied config unless the
+// caller chose one or the model does not support it.
+func (es *GeminiEmbeddingClient) withDimension(cfg *genai.EmbedContentConfig) *genai.EmbedContentConfig {
+	if cfg.OutputDimensionality != nil || es.dimension <= 0 || !isMatryoshkaModel(es.model) {
+		return cfg
+	}
+	out := *cfg
+	out.OutputDimensionality = genai.Ptr(int32(es.dimension))
+	return &out
+}
+
+// fitDimension checks values against the dimensionality requested by cfg,
+// truncating and renormalizing vectors from Matryoshka models.
+func (es *GeminiEmbeddingClient) fitDimension(values []float32, cfg *genai.EmbedContentConfig) ([]float32, error) {
+	want := es.dimension
+	if cfg != nil && cfg.OutputDimensionality != nil {
+		want = int(*cfg.OutputDimensionality)
+	}
+	if want <= 0 {
+		return values, nil
+	}
+	if !isMatryoshkaModel(es.model) {
+		if len(values) != want {
+			return nil, &EmbeddingDimensionError{Model: es.model, Want: want, Got: len(values)}
+		}
+		return values, nil
+	}
+	if len(values) < want {
+		return nil, &EmbeddingDimensionError{Model: es.model, Want: want, Got: len(values)}
+	}
+	return normalizeL2(values[:want:want]), nil
+}
+
+// isMatryoshkaModel reports whether the model's vectors stay meaningful when
+// truncated, so it accepts OutputDimensionality.
+func isMatryoshkaModel(model string) bool {
+	model = strings.TrimPrefix(model, "models/")
+	if strings.HasPrefix(model, "gemini-embedding") {
+		return true
+	}
+	for _, m := range []string{"text-embedding-004", "text-embedding-005", "text-multilingual-embedding-002"} {
+		if strings.HasPrefix(model, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeL2 scales v to unit length in place. Only the full-size output of
+// a Matryoshka model is normalized by the API.
+func normalizeL2(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	inv := 1 / math.Sqrt(sum)
+	for i, x := range v {
+		v[i] = float32(float64(x) * inv)
+	}
+	return v
+}
@@ -0,0 +1,73 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFitDimension(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   string
+		dim     int
+		values  []float32
+		want    []float32
+		wantErr bool
+	}{
+		{"disabled", EmbeddingModel, 0, []float32{3, 4, 12}, []float32{3, 4, 12}, false},
+		{"matryoshka renormalized", EmbeddingModel, 2, []float32{3, 4}, []float32{0.6, 0.8}, false},
+		{"matryoshka truncated", "models/text-embedding-004", 2, []float32{3, 4, 12}, []float32{0.6, 0.8}, false},
+		{"matryoshka too short", EmbeddingModel, 3, []float32{3, 4}, nil, true},
+		{"fixed size match", "embedding-001", 3, []float32{3, 4, 12}, []float32{3, 4, 12}, false},
+		{"fixed size mismatch", "embedding-001", 2, []float32{3, 4, 12}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := &GeminiEmbeddingClient{model: tt.model, dimension: tt.dim}
+			got, err := es.fitDimension(tt.values, nil)
+			if tt.wantErr {
+				var dimErr *EmbeddingDimensionError
+				if !errors.As(err, &dimErr) || dimErr.Want != tt.dim || dimErr.Got != len(tt.values) {
+					t.Errorf("expected *EmbeddingDimensionError, got %v", err)
+				}
+				return
+			}
+			if err != nil || len(got) != len(tt.want) {
+				t.Fatalf("got %v, %v; want %v", got, err, tt.want)
+			}
+			for i := range got {
+				if math.Abs(float64(got[i]-tt.want[i])) > 1e-6 {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddingClient_SendsOutputDimensionality(t *testing.T) {
+	es, _, contents := newTestEmbeddingServer(t, "")
+	ctx := context.Background()
+
+	es.WithDimension(2)
+	vec, err := es.GenerateQueryEmbedding(ctx, "museums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (*contents)[0].OutputDimensionality; got == nil || *got != 2 {
+		t.Errorf("outputDimensionality = %v, want 2", got)
+	}
+	if norm := math.Hypot(float64(vec[0]), float64(vec[1])); math.Abs(norm-1) > 1e-6 {
+		t.Errorf("vector %v is not unit length", vec)
+	}
+
+	// The test server ignores the requested size, like a model that does not
+	// support it.
+	es.WithDimension(EmbeddingDimension)
+	_, err = es.BatchGenerateEmbeddings(ctx, []string{"a", "b"})
+	var dimErr *EmbeddingDimensionError
+	if !errors.As(err, &dimErr) || dimErr.Got != 2 || dimErr.Want != EmbeddingDimension {
+		t.Errorf("expected *EmbeddingDimensionError, got %v", err)
+	}
+}
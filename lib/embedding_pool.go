@@ -0,0 +1,99 @@
+package genai_sdk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EmbeddingPoolOptions configures how BatchGenerateEmbeddings spreads its
+// requests over concurrent workers.
+type EmbeddingPoolOptions struct {
+	// Concurrency is the number of requests in flight; defaults to 1.
+	Concurrency int
+	// RequestsPerMinute caps request attempts, retries included. Zero means
+	// no limit.
+	RequestsPerMinute int
+	// TokensPerMinute caps estimated input tokens sent. Zero means no limit.
+	TokensPerMinute int
+	// OnProgress is called after each request completes or fails. Calls are
+	// serialised but come from worker goroutines.
+	OnProgress func(EmbeddingProgress)
+	// Clock drives rate limit waits; defaults to the system clock.
+	Clock Clock
+}
+
+// EmbeddingProgress counts texts handled so far by a batch.
+type EmbeddingProgress struct {
+	Done   int
+	Failed int
+	Total  int
+}
+
+// WithWorkerPool makes BatchGenerateEmbeddings send requests concurrently
+// under opts. The rate limits are shared by every call on this client,
+// single GenerateEmbedding calls included.
+func (es *GeminiEmbeddingClient) WithWorkerPool(opts EmbeddingPoolOptions) *GeminiEmbeddingClient {
+	es.pool = opts
+	clock := opts.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	es.requestLimit = newMinuteLimiter(opts.RequestsPerMinute, clock)
+	es.tokenLimit = newMinuteLimiter(opts.TokensPerMinute, clock)
+	return es
+}
+
+// waitRateLimit blocks until one request of tokens may be sent.
+func (es *GeminiEmbeddingClient) waitRateLimit(ctx context.Context, tokens int) error {
+	if err := es.requestLimit.wait(ctx, 1); err != nil {
+		return err
+	}
+	return es.tokenLimit.wait(ctx, tokens)
+}
+
+// minuteLimiter is a token bucket holding up to limit units that refills at
+// limit per minute. A nil limiter never blocks.
+type minuteLimiter struct {
+	limit float64
+	clock Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newMinuteLimiter(limit int, clock Clock) *minuteLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &minuteLimiter{limit: float64(limit), clock: clock, tokens: float64(limit), last: clock.Now()}
+}
+
+// wait takes n units, blocking until they are available. A request larger
+// than the limit waits for a full bucket and takes all of it.
+func (l *minuteLimiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	need := min(float64(n), l.limit)
+	for {
+		l.mu.Lock()
+		now := l.clock.Now()
+		l.tokens = min(l.limit, l.tokens+now.Sub(l.last).Minutes()*l.limit)
+		l.last = now
+		if l.tokens >= need {
+			l.tokens -= need
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((need - l.tokens) / l.limit * float64(time.Minute))
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-l.clock.After(delay):
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package genai_sdk
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMinuteLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := newMinuteLimiter(60, clock) // one per second
+	ctx := context.Background()
+
+	for range 60 {
+		if err := l.wait(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(clock.waits) != 0 {
+		t.Fatalf("burst up to the limit waited %v", clock.waits)
+	}
+
+	if err := l.wait(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(clock.waits, []time.Duration{3 * time.Second}) {
+		t.Errorf("waits = %v, want [3s]", clock.waits)
+	}
+
+	// A request above the limit waits for a full bucket instead of forever.
+	clock.waits = nil
+	if err := l.wait(ctx, 500); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(clock.waits, []time.Duration{time.Minute}) {
+		t.Errorf("waits = %v, want [1m0s]", clock.waits)
+	}
+
+	if newMinuteLimiter(0, clock).wait(ctx, 1_000) != nil {
+		t.Error("unlimited limiter should not block")
+	}
+}
+
+func TestMinuteLimiter_ContextCancelled(t *testing.T) {
+	l := newMinuteLimiter(1, realClock{})
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = l.wait(ctx, 1)
+	cancel()
+	if err := l.wait(ctx, 1); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestEmbedBatches_Concurrent(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	texts := make([]string, 50)
+	for i := range texts {
+		texts[i] = string(rune('A' + i))
+	}
+
+	var inFlight, peak atomic.Int32
+	embed := func(_ context.Context, _ int, batch []string) ([][]float32, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		values := make([][]float32, len(batch))
+		for i, text := range batch {
+			values[i] = []float32{float32(text[0])}
+		}
+		return values, nil
+	}
+
+	var mu sync.Mutex
+	var updates []EmbeddingProgress
+	pool := EmbeddingPoolOptions{
+		Concurrency: 4,
+		OnProgress: func(p EmbeddingProgress) {
+			mu.Lock()
+			updates = append(updates, p)
+			mu.Unlock()
+		},
+	}
+	got, err := embedBatches(context.Background(), logger, texts, EmbeddingBatchLimits{MaxItems: 3}, pool, embed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range texts {
+		if got[i][0] != float32(text[0]) {
+			t.Fatalf("embedding %d out of order: %v", i, got[i])
+		}
+	}
+	if p := peak.Load(); p > 4 || p < 2 {
+		t.Errorf("peak concurrency = %d, want 2..4", p)
+	}
+	last := updates[len(updates)-1]
+	if len(updates) != 17 || last != (EmbeddingProgress{Done: 50, Total: 50}) {
+		t.Errorf("%d progress updates, last %+v", len(updates), last)
+	}
+}
+
+func TestEmbedBatches_ContextCancelled(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	embed := func(ctx context.Context, _ int, batch []string) ([][]float32, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	texts := []string{"a", "b", "c", "d"}
+	got, err := embedBatches(ctx, logger, texts, EmbeddingBatchLimits{MaxItems: 1}, EmbeddingPoolOptions{Concurrency: 2}, embed)
+	if err != context.Canceled || got != nil {
+		t.Errorf("got %v, %v; want context.Canceled", got, err)
+	}
+}
+
+func TestGenerateEmbedding_SharesRateLimit(t *testing.T) {
+	es, _, _ := newTestEmbeddingServer(t, "")
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	es.WithWorkerPool(EmbeddingPoolOptions{RequestsPerMinute: 1, Clock: clock})
+
+	for range 2 {
+		if _, err := es.GenerateEmbedding(context.Background(), "hello", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(clock.waits, []time.Duration{time.Minute}) {
+		t.Errorf("waits = %v, want the second call to wait [1m0s]", clock.waits)
+	}
+}
@@ -0,0 +1,113 @@
+package genai_sdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genai"
+)
+
+// Embedding task types tell the model how a vector will be compared. Queries
+// and the documents they retrieve should be embedded asymmetrically, with
+// EmbeddingTaskRetrievalQuery and EmbeddingTaskRetrievalDocument.
+const (
+	EmbeddingTaskRetrievalQuery     = "RETRIEVAL_QUERY"
+	EmbeddingTaskRetrievalDocument  = "RETRIEVAL_DOCUMENT"
+	EmbeddingTaskSemanticSimilarity = "SEMANTIC_SIMILARITY"
+	EmbeddingTaskClassification     = "CLASSIFICATION"
+	EmbeddingTaskClustering         = "CLUSTERING"
+	EmbeddingTaskQuestionAnswering  = "QUESTION_ANSWERING"
+	EmbeddingTaskFactVerification   = "FACT_VERIFICATION"
+	EmbeddingTaskCodeRetrievalQuery = "CODE_RETRIEVAL_QUERY"
+)
+
+// WithTaskType returns a copy of cfg with the task type and title set. title
+// is only sent for EmbeddingTaskRetrievalDocument.
+func WithTaskType(cfg *genai.EmbedContentConfig, taskType, title string) *genai.EmbedContentConfig {
+	out := &genai.EmbedContentConfig{}
+	if cfg != nil {
+		*out = *cfg
+	}
+	out.TaskType = taskType
+	out.Title = ""
+	if taskType == EmbeddingTaskRetrievalDocument {
+		out.Title = title
+	}
+	return out
+}
+
+// embeddingConfig fills in the task type and title a caller left empty. A
+// config that sets a title without a task type is a document.
+func embeddingConfig(cfg *genai.EmbedContentConfig, taskType, title string) *genai.EmbedContentConfig {
+	out := &genai.EmbedContentConfig{}
+	if cfg != nil {
+		*out = *cfg
+	}
+	if out.TaskType == "" {
+		out.TaskType = taskType
+		if out.Title != "" {
+			out.TaskType = EmbeddingTaskRetrievalDocument
+		}
+	}
+	if out.TaskType != EmbeddingTaskRetrievalDocument {
+		out.Title = ""
+	} else if out.Title == "" {
+		out.Title = title
+	}
+	return out
+}
+
+// EmbeddingInput is a text embedded as a retrieval document under its own
+// title, see BatchGenerateDocumentEmbeddings.
+type EmbeddingInput struct {
+	Text  string
+	Title string
+}
+
+// withTitles returns a copy of cfg whose request gives each text its own
+// title. EmbedContentConfig carries one title for the whole call, but the
+// batch request has a title per entry, so the entries are patched directly.
+// The request body is built by the SDK, so check reports an error after a
+// call whose body did not have the expected entries and went without titles.
+func withTitles(cfg *genai.EmbedContentConfig, titles []string) (out *genai.EmbedContentConfig, check func() error) {
+	opts := genai.HTTPOptions{}
+	if cfg.HTTPOptions != nil {
+		opts = *cfg.HTTPOptions
+	}
+	// The SDK fills in Headers on the options it is given; don't share them.
+	opts.Headers = opts.Headers.Clone()
+	if opts.Headers == nil {
+		opts.Headers = http.Header{}
+	}
+	applied := false
+	next := opts.ExtrasRequestProvider
+	opts.ExtrasRequestProvider = func(body map[string]any) map[string]any {
+		if next != nil {
+			body = next(body)
+		}
+		applied = false
+		// Gemini API batches use "requests"; Vertex AI predict uses "instances".
+		for _, key := range []string{"requests", "instances"} {
+			entries, ok := body[key].([]map[string]any)
+			if !ok || len(entries) != len(titles) {
+				continue
+			}
+			for i, title := range titles {
+				if title != "" {
+					entries[i]["title"] = title
+				}
+			}
+			applied = true
+		}
+		return body
+	}
+	out = new(genai.EmbedContentConfig)
+	*out = *cfg
+	out.HTTPOptions = &opts
+	return out, func() error {
+		if !applied {
+			return fmt.Errorf("failed to set per-text titles: unexpected embedding request shape")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package genai_sdk
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestEmbeddingConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *genai.EmbedContentConfig
+		task      string
+		title     string
+		wantTask  string
+		wantTitle string
+	}{
+		{"nil config takes defaults", nil, EmbeddingTaskRetrievalQuery, "", EmbeddingTaskRetrievalQuery, ""},
+		{"document default title", nil, EmbeddingTaskRetrievalDocument, "Belém Tower", EmbeddingTaskRetrievalDocument, "Belém Tower"},
+		{"caller task wins", &genai.EmbedContentConfig{TaskType: EmbeddingTaskClustering}, EmbeddingTaskRetrievalDocument, "Belém Tower", EmbeddingTaskClustering, ""},
+		{"caller title wins", &genai.EmbedContentConfig{Title: "Torre"}, EmbeddingTaskRetrievalDocument, "Belém Tower", EmbeddingTaskRetrievalDocument, "Torre"},
+		{"title implies document", &genai.EmbedContentConfig{Title: "Torre"}, EmbeddingTaskSemanticSimilarity, "", EmbeddingTaskRetrievalDocument, "Torre"},
+		{"title dropped for queries", &genai.EmbedContentConfig{TaskType: EmbeddingTaskRetrievalQuery, Title: "Torre"}, "", "", EmbeddingTaskRetrievalQuery, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := embeddingConfig(tt.cfg, tt.task, tt.title)
+			if got.TaskType != tt.wantTask || got.Title != tt.wantTitle {
+				t.Errorf("got task %q title %q, want %q %q", got.TaskType, got.Title, tt.wantTask, tt.wantTitle)
+			}
+			if tt.cfg != nil && got == tt.cfg {
+				t.Error("config was not copied")
+			}
+		})
+	}
+}
+
+func TestEmbeddingClient_TaskTypes(t *testing.T) {
+	es, _, contents := newTestEmbeddingServer(t, "")
+	ctx := context.Background()
+
+	errOf := func(_ any, err error) error { return err }
+	calls := []struct {
+		name      string
+		call      func() error
+		wantTask  string
+		wantTitle string
+	}{
+		{"query", func() error { return errOf(es.GenerateQueryEmbedding(ctx, "cafés near Rossio")) }, EmbeddingTaskRetrievalQuery, ""},
+		{"poi", func() error { return errOf(es.GeneratePOIEmbedding(ctx, "Belém Tower", "Fortress", "landmark")) }, EmbeddingTaskRetrievalDocument, "Belém Tower"},
+		{"city", func() error { return errOf(es.GenerateCityEmbedding(ctx, "Lisbon", "Portugal", "")) }, EmbeddingTaskRetrievalDocument, "Lisbon"},
+		{"preferences", func() error { return errOf(es.GenerateUserPreferenceEmbedding(ctx, []string{"art"}, nil)) }, EmbeddingTaskSemanticSimilarity, ""},
+		{"generic", func() error { return errOf(es.GenerateEmbedding(ctx, "hello", nil)) }, "", ""},
+		{"override", func() error {
+			return errOf(es.GenerateEmbedding(ctx, "hello", WithTaskType(nil, EmbeddingTaskClustering, "")))
+		}, EmbeddingTaskClustering, ""},
+		{"batch", func() error { return errOf(es.BatchGenerateEmbeddings(ctx, []string{"a"})) }, EmbeddingTaskRetrievalDocument, ""},
+		{"batch override", func() error {
+			return errOf(es.BatchGenerateEmbeddingsWithConfig(ctx, []string{"a"}, WithTaskType(nil, EmbeddingTaskClassification, "")))
+		}, EmbeddingTaskClassification, ""},
+	}
+	for i, c := range calls {
+		if err := c.call(); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		got := (*contents)[i]
+		if got.TaskType != c.wantTask || got.Title != c.wantTitle {
+			t.Errorf("%s: sent task %q title %q, want %q %q", c.name, got.TaskType, got.Title, c.wantTask, c.wantTitle)
+		}
+	}
+}
+
+func TestBatchGenerateDocumentEmbeddings_PerTextTitles(t *testing.T) {
+	es, _, contents := newTestEmbeddingServer(t, "")
+	docs := []EmbeddingInput{
+		{Text: "Manueline fortress on the Tagus", Title: "Belém Tower"},
+		{Text: "Untitled note"},
+		{Text: "Monastery of the Hieronymites", Title: "Jerónimos Monastery"},
+	}
+	vecs, err := es.BatchGenerateDocumentEmbeddings(context.Background(), docs)
+	if err != nil || len(vecs) != len(docs) {
+		t.Fatalf("got %d vectors, err %v", len(vecs), err)
+	}
+	if len(*contents) != len(docs) {
+		t.Fatalf("sent %d contents, want %d in one request", len(*contents), len(docs))
+	}
+	for i, got := range *contents {
+		if got.TaskType != EmbeddingTaskRetrievalDocument || got.Title != docs[i].Title {
+			t.Errorf("content %d: sent task %q title %q, want %q %q", i, got.TaskType, got.Title, EmbeddingTaskRetrievalDocument, docs[i].Title)
+		}
+	}
+}
+
+func TestWithTitles(t *testing.T) {
+	cfg, check := withTitles(&genai.EmbedContentConfig{TaskType: EmbeddingTaskRetrievalDocument}, []string{"Belém Tower", ""})
+	provide := cfg.HTTPOptions.ExtrasRequestProvider
+
+	body := provide(map[string]any{"requests": []map[string]any{{}, {}}})
+	entries := body["requests"].([]map[string]any)
+	if entries[0]["title"] != "Belém Tower" || entries[1]["title"] != nil {
+		t.Errorf("entries = %v, want only the first titled", entries)
+	}
+	if err := check(); err != nil {
+		t.Errorf("check after a patched request: %v", err)
+	}
+
+	// A body the titles cannot be matched to must not go unnoticed.
+	provide(map[string]any{"requests": []any{map[string]any{}, map[string]any{}}})
+	if err := check(); err == nil {
+		t.Error("expected an error when the request shape is not recognised")
+	}
+	provide(map[string]any{"requests": []map[string]any{{}}})
+	if err := check(); err == nil {
+		t.Error("expected an error when the entry count does not match")
+	}
+}
@@ -0,0 +1,157 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Sentinel errors describing the class of a failed Gemini call. Errors
+// returned by the clients wrap one of these, so callers can branch with
+// errors.Is without inspecting status codes or messages.
+var (
+	ErrRateLimited      = errors.New("rate limited")
+	ErrQuotaExhausted   = errors.New("quota exhausted")
+	ErrInvalidArgument  = errors.New("invalid argument")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrNotFound         = errors.New("not found")
+	ErrServerError      = errors.New("server error")
+	ErrTimeout          = errors.New("timeout")
+)
+
+const (
+	retryInfoType    = "type.googleapis.com/google.rpc.RetryInfo"
+	quotaFailureType = "type.googleapis.com/google.rpc.QuotaFailure"
+)
+
+// Error is a classified Gemini failure. It unwraps to both its Kind sentinel
+// and the underlying error (usually a genai.APIError).
+type Error struct {
+	// Kind is one of the Err* sentinels above.
+	Kind error
+	// Code is the HTTP status code, or 0 when the failure did not come from the API.
+	Code int
+	// Status is the canonical API status, e.g. "RESOURCE_EXHAUSTED".
+	Status string
+	// RetryAfter is the server-suggested delay from RetryInfo, or 0 when absent.
+	RetryAfter time.Duration
+	// Err is the original error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v (retry after %s): %v", e.Kind, e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// ClassifyError wraps err in an *Error when it can be mapped to one of the
+// sentinel kinds. Unknown errors, context errors, and errors that are already
+// classified are returned unchanged.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		kind := apiErrorKind(apiErr)
+		if kind == nil {
+			return err
+		}
+		return &Error{
+			Kind:       kind,
+			Code:       apiErr.Code,
+			Status:     apiErr.Status,
+			RetryAfter: RetryAfter(apiErr),
+			Err:        err,
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &Error{Kind: ErrTimeout, Err: err}
+	}
+	return err
+}
+
+// RetryAfter returns the retry delay suggested by the server through a
+// google.rpc.RetryInfo detail, or 0 when err carries none.
+func RetryAfter(err error) time.Duration {
+	var classified *Error
+	if errors.As(err, &classified) && classified.RetryAfter > 0 {
+		return classified.RetryAfter
+	}
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return 0
+	}
+	for _, detail := range apiErr.Details {
+		if detail["@type"] != retryInfoType {
+			continue
+		}
+		raw, _ := detail["retryDelay"].(string)
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func apiErrorKind(apiErr genai.APIError) error {
+	switch {
+	case apiErr.Code == 429 || apiErr.Status == "RESOURCE_EXHAUSTED":
+		if isQuotaExhausted(apiErr) {
+			return ErrQuotaExhausted
+		}
+		return ErrRateLimited
+	case apiErr.Code == 504 || apiErr.Status == "DEADLINE_EXCEEDED":
+		return ErrTimeout
+	case apiErr.Code >= 500:
+		return ErrServerError
+	case apiErr.Code == 400 || apiErr.Status == "INVALID_ARGUMENT" || apiErr.Status == "FAILED_PRECONDITION":
+		return ErrInvalidArgument
+	case apiErr.Code == 401 || apiErr.Code == 403 || apiErr.Status == "PERMISSION_DENIED" || apiErr.Status == "UNAUTHENTICATED":
+		return ErrPermissionDenied
+	case apiErr.Code == 404 || apiErr.Status == "NOT_FOUND":
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isQuotaExhausted distinguishes a hard quota (e.g. requests per day) from a
+// short-window rate limit. Only per-minute limits are worth retrying; a daily
+// quota will not recover within any reasonable backoff.
+func isQuotaExhausted(apiErr genai.APIError) bool {
+	for _, detail := range apiErr.Details {
+		if detail["@type"] != quotaFailureType {
+			continue
+		}
+		violations, _ := detail["violations"].([]any)
+		for _, v := range violations {
+			violation, _ := v.(map[string]any)
+			quotaID, _ := violation["quotaId"].(string)
+			if strings.Contains(quotaID, "PerDay") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestClassifyError(t *testing.T) {
+	dailyQuota := genai.APIError{
+		Code:   429,
+		Status: "RESOURCE_EXHAUSTED",
+		Details: []map[string]any{{
+			"@type": quotaFailureType,
+			"violations": []any{
+				map[string]any{"quotaId": "GenerateRequestsPerDayPerProjectPerModel-FreeTier"},
+			},
+		}},
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"429", genai.APIError{Code: 429}, ErrRateLimited},
+		{"daily quota", dailyQuota, ErrQuotaExhausted},
+		{"400", genai.APIError{Code: 400, Status: "INVALID_ARGUMENT"}, ErrInvalidArgument},
+		{"403", genai.APIError{Code: 403}, ErrPermissionDenied},
+		{"404", genai.APIError{Code: 404}, ErrNotFound},
+		{"500", genai.APIError{Code: 500}, ErrServerError},
+		{"503", genai.APIError{Code: 503}, ErrServerError},
+		{"504", genai.APIError{Code: 504}, ErrTimeout},
+		{"wrapped 503", fmt.Errorf("call failed: %w", genai.APIError{Code: 503}), ErrServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("ClassifyError(%v) = %v, want kind %v", tt.err, got, tt.want)
+			}
+			var apiErr genai.APIError
+			if !errors.As(got, &apiErr) {
+				t.Errorf("classified error should still unwrap to genai.APIError")
+			}
+		})
+	}
+}
+
+func TestClassifyError_Passthrough(t *testing.T) {
+	for _, err := range []error{nil, context.Canceled, errors.New("plain"), genai.APIError{Code: 409}} {
+		var classified *Error
+		if errors.As(ClassifyError(err), &classified) {
+			t.Errorf("ClassifyError(%v) = %v, want unchanged", err, classified)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	err := genai.APIError{
+		Code: 429,
+		Details: []map[string]any{
+			{"@type": retryInfoType, "retryDelay": "37s"},
+		},
+	}
+	if got := RetryAfter(err); got != 37*time.Second {
+		t.Errorf("RetryAfter = %v, want 37s", got)
+	}
+	var classified *Error
+	if !errors.As(ClassifyError(err), &classified) || classified.RetryAfter != 37*time.Second {
+		t.Errorf("classified RetryAfter = %+v, want 37s", classified)
+	}
+	if got := RetryAfter(genai.APIError{Code: 429}); got != 0 {
+		t.Errorf("RetryAfter without RetryInfo = %v, want 0", got)
+	}
+}
+
+func TestIsRetryable_QuotaExhaustedNotRetried(t *testing.T) {
+	err := genai.APIError{
+		Code: 429,
+		Details: []map[string]any{{
+			"@type":      quotaFailureType,
+			"violations": []any{map[string]any{"quotaId": "RequestsPerDay"}},
+		}},
+	}
+	if IsRetryable(err) {
+		t.Error("daily quota exhaustion should not be retryable")
+	}
+}
+
+func TestRetryWithBackoff_HonorsRetryAfter(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	_, err := retryWithBackoff(context.Background(), fastPolicy, nil, "test",
+		func(context.Context) (string, error) {
+			calls++
+			if calls == 1 {
+				return "", genai.APIError{
+					Code:    429,
+					Details: []map[string]any{{"@type": retryInfoType, "retryDelay": "0.05s"}},
+				}
+			}
+			return "ok", nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("retry did not wait for server delay; elapsed %v", elapsed)
+	}
+}
+
+func TestRetryWithBackoff_ReturnsClassifiedError(t *testing.T) {
+	_, err := retryWithBackoff(context.Background(), fastPolicy, nil, "test",
+		func(context.Context) (string, error) {
+			return "", genai.APIError{Code: 400}
+		})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -9,36 +9,66 @@ import (
 )
 
 // ExtractText returns concatenated text from the first candidate with content.
+// Thought summaries are excluded; use ExtractThoughts to read them.
 func ExtractText(resp *genai.GenerateContentResponse) (string, error) {
-	if resp == nil {
-		return "", fmt.Errorf("response is nil")
+	_, text, err := ExtractThoughtsAndText(resp)
+	return text, err
+}
+
+// ExtractUsage returns prompt, completion, and total token counts when present.
+func ExtractUsage(resp *genai.GenerateContentResponse) (prompt, completion, total int) {
+	if resp == nil || resp.UsageMetadata == nil {
+		return 0, 0, 0
 	}
+	um := resp.UsageMetadata
+	return int(um.PromptTokenCount), int(um.CandidatesTokenCount), int(um.TotalTokenCount)
+}
 
-	var b strings.Builder
+// answerCandidate returns the first candidate with answer text, which is the
+// candidate ExtractText reads, along with its split thought and answer text.
+func answerCandidate(resp *genai.GenerateContentResponse) (cand *genai.Candidate, thoughts, text string, err error) {
+	if resp == nil {
+		return nil, "", "", fmt.Errorf("response is nil")
+	}
 	for _, cand := range resp.Candidates {
-		if cand.Content == nil {
+		if cand == nil {
 			continue
 		}
-		for _, part := range cand.Content.Parts {
-			if part.Text != "" {
-				b.WriteString(part.Text)
-			}
-		}
-		if b.Len() > 0 {
-			return b.String(), nil
+		thoughts, text = splitParts(cand.Content)
+		if text != "" {
+			return cand, thoughts, text, nil
 		}
 	}
+	return nil, "", "", fmt.Errorf("no text content in response")
+}
 
-	return "", fmt.Errorf("no text content in response")
+// Usage is the full token accounting of a response.
+type Usage struct {
+	Prompt int
+	// Candidates counts answer tokens only; thinking is reported in Thoughts.
+	Candidates int
+	Thoughts   int
+	// Cached is the part of Prompt served from the context cache.
+	Cached  int
+	ToolUse int
+	Total   int
 }
 
-// ExtractUsage returns prompt, completion, and total token counts when present.
-func ExtractUsage(resp *genai.GenerateContentResponse) (prompt, completion, total int) {
+// ExtractUsageDetails returns every token count reported by the response,
+// including thinking and cached tokens that ExtractUsage leaves out.
+func ExtractUsageDetails(resp *genai.GenerateContentResponse) Usage {
 	if resp == nil || resp.UsageMetadata == nil {
-		return 0, 0, 0
+		return Usage{}
 	}
 	um := resp.UsageMetadata
-	return int(um.PromptTokenCount), int(um.CandidatesTokenCount), int(um.TotalTokenCount)
+	return Usage{
+		Prompt:     int(um.PromptTokenCount),
+		Candidates: int(um.CandidatesTokenCount),
+		Thoughts:   int(um.ThoughtsTokenCount),
+		Cached:     int(um.CachedContentTokenCount),
+		ToolUse:    int(um.ToolUsePromptTokenCount),
+		Total:      int(um.TotalTokenCount),
+	}
 }
 
 // ConcatStreamText drains a generate-content stream into a single string.
@@ -0,0 +1,358 @@
+package genai_sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// FileTTL is how long the Files API keeps an upload.
+const FileTTL = 48 * time.Hour
+
+// FileRecord maps a content hash to an uploaded file. Hash is the SHA-256 of
+// the MIME type and the content, so the same bytes uploaded as different
+// types are kept apart.
+type FileRecord struct {
+	Hash        string    `json:"hash"`
+	Name        string    `json:"name"`
+	URI         string    `json:"uri"`
+	MIMEType    string    `json:"mime_type"`
+	DisplayName string    `json:"display_name,omitempty"`
+	SizeBytes   int64     `json:"size_bytes"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// FileStore persists FileRecords by content hash.
+type FileStore interface {
+	Get(ctx context.Context, hash string) (FileRecord, bool, error)
+	Put(ctx context.Context, rec FileRecord) error
+	Delete(ctx context.Context, hash string) error
+}
+
+// FileRegistry uploads content once per SHA-256 hash and MIME type and reuses
+// the remote file until it is about to expire, after which it is uploaded
+// again.
+type FileRegistry struct {
+	files  FileClient
+	store  FileStore
+	margin time.Duration
+	now    func() time.Time
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	locks map[string]*hashLock
+}
+
+// hashLock serialises uploads of one hash; refs counts holders and waiters so
+// the entry can be dropped once nobody needs it.
+type hashLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewFileRegistry creates a registry over files. store defaults to an
+// in-memory store.
+func NewFileRegistry(files FileClient, store FileStore) *FileRegistry {
+	if store == nil {
+		store = NewMemoryFileStore()
+	}
+	return &FileRegistry{
+		files:  files,
+		store:  store,
+		margin: time.Hour,
+		now:    time.Now,
+		logger: slog.Default(),
+		locks:  make(map[string]*hashLock),
+	}
+}
+
+// WithExpiryMargin re-uploads files that expire within d, so a returned file
+// stays usable for at least that long. Defaults to one hour.
+func (r *FileRegistry) WithExpiryMargin(d time.Duration) *FileRegistry {
+	if d >= 0 {
+		r.margin = d
+	}
+	return r
+}
+
+// WithLogger sets the logger used for registry diagnostics.
+func (r *FileRegistry) WithLogger(logger *slog.Logger) *FileRegistry {
+	if logger != nil {
+		r.logger = logger
+	}
+	return r
+}
+
+// Upload returns the remote file for the content of rd, uploading it only if
+// no live upload with the same hash and MIME type is known. Seekable readers
+// are hashed and rewound; others are buffered in memory.
+func (r *FileRegistry) Upload(ctx context.Context, rd io.Reader, mimeType, displayName string) (*genai.File, error) {
+	if mimeType == "" {
+		return nil, fmt.Errorf("MIME type is required")
+	}
+	hash, body, err := hashContent(rd, mimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := r.lock(hash)
+	defer unlock()
+
+	if file, ok, err := r.lookup(ctx, hash); err != nil || ok {
+		return file, err
+	}
+
+	uploaded, err := r.files.Upload(ctx, body, mimeType, displayName)
+	if err != nil {
+		return nil, err
+	}
+	file, err := r.files.WaitUntilActive(ctx, uploaded.Name)
+	if err != nil {
+		// The file is not recorded, so nothing would ever reuse or delete
+		// it. The delete is best effort and outlives a cancelled ctx.
+		_ = r.files.Delete(context.WithoutCancel(ctx), uploaded.Name)
+		return nil, err
+	}
+
+	now := r.now()
+	rec := FileRecord{
+		Hash:        hash,
+		Name:        file.Name,
+		URI:         file.URI,
+		MIMEType:    file.MIMEType,
+		DisplayName: displayName,
+		UploadedAt:  now,
+		ExpiresAt:   file.ExpirationTime,
+	}
+	if rec.ExpiresAt.IsZero() {
+		rec.ExpiresAt = now.Add(FileTTL)
+	}
+	if file.SizeBytes != nil {
+		rec.SizeBytes = *file.SizeBytes
+	}
+	if err := r.store.Put(ctx, rec); err != nil {
+		r.logger.WarnContext(ctx, "failed to record uploaded file",
+			slog.String("file", file.Name),
+			slog.Any("error", err))
+	}
+	return file, nil
+}
+
+// UploadFromPath uploads a local file through the registry. mimeType is
+// inferred from the extension when empty.
+func (r *FileRegistry) UploadFromPath(ctx context.Context, path, mimeType string) (*genai.File, error) {
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer f.Close()
+	return r.Upload(ctx, f, mimeType, filepath.Base(path))
+}
+
+// lookup returns the remote file recorded for hash if it is still active and
+// not about to expire. Records that are expiring, gone or no longer ACTIVE
+// are dropped; other errors checking the file are returned and the record is
+// kept.
+func (r *FileRegistry) lookup(ctx context.Context, hash string) (*genai.File, bool, error) {
+	rec, ok, err := r.store.Get(ctx, hash)
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to read file registry",
+			slog.String("hash", hash),
+			slog.Any("error", err))
+		return nil, false, nil
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	if !rec.ExpiresAt.After(r.now().Add(r.margin)) {
+		r.logger.DebugContext(ctx, "Registered file expiring, re-uploading",
+			slog.String("file", rec.Name),
+			slog.Time("expires_at", rec.ExpiresAt))
+		_ = r.store.Delete(ctx, hash)
+		return nil, false, nil
+	}
+	file, err := r.files.Get(ctx, rec.Name)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return nil, false, fmt.Errorf("failed to check registered file %s: %w", rec.Name, err)
+	case file.State == genai.FileStateActive:
+		return file, true, nil
+	default:
+		r.logger.DebugContext(ctx, "Registered file not active, re-uploading",
+			slog.String("file", rec.Name),
+			slog.String("state", string(file.State)))
+	}
+	_ = r.store.Delete(ctx, hash)
+	return nil, false, nil
+}
+
+// lock acquires the per-hash upload lock and returns its release function,
+// which drops the map entry once no other upload is using it.
+func (r *FileRegistry) lock(hash string) (unlock func()) {
+	r.mu.Lock()
+	l, ok := r.locks[hash]
+	if !ok {
+		l = &hashLock{}
+		r.locks[hash] = l
+	}
+	l.refs++
+	r.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(r.locks, hash)
+		}
+	}
+}
+
+// hashContent returns the hex SHA-256 of mimeType and rd's content, and a
+// reader positioned at the start of the same content.
+func hashContent(rd io.Reader, mimeType string) (string, io.Reader, error) {
+	if rd == nil {
+		return "", nil, fmt.Errorf("reader is nil")
+	}
+	h := sha256.New()
+	h.Write([]byte(mimeType))
+	h.Write([]byte{0})
+	if seeker, ok := rd.(io.ReadSeeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			if _, err := io.Copy(h, seeker); err != nil {
+				return "", nil, fmt.Errorf("failed to hash content: %w", err)
+			}
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return "", nil, fmt.Errorf("failed to rewind content: %w", err)
+			}
+			return hex.EncodeToString(h.Sum(nil)), seeker, nil
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(io.MultiWriter(h, &buf), rd); err != nil {
+		return "", nil, fmt.Errorf("failed to hash content: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), &buf, nil
+}
+
+// MemoryFileStore is a FileStore that lives for the process lifetime.
+type MemoryFileStore struct {
+	mu      sync.RWMutex
+	records map[string]FileRecord
+}
+
+// NewMemoryFileStore creates an empty in-memory store.
+func NewMemoryFileStore() *MemoryFileStore {
+	return &MemoryFileStore{records: make(map[string]FileRecord)}
+}
+
+func (s *MemoryFileStore) Get(_ context.Context, hash string) (FileRecord, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, ok := s.records[hash]
+	return rec, ok, nil
+}
+
+func (s *MemoryFileStore) Put(_ context.Context, rec FileRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[rec.Hash] = rec
+	return nil
+}
+
+func (s *MemoryFileStore) Delete(_ context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, hash)
+	return nil
+}
+
+// JSONFileStore is a FileStore persisted as a JSON file, so uploads survive
+// restarts. Writes replace the file atomically.
+type JSONFileStore struct {
+	path string
+	mem  *MemoryFileStore
+	mu   sync.Mutex
+}
+
+// NewJSONFileStore loads the store at path, starting empty if it does not exist.
+func NewJSONFileStore(path string) (*JSONFileStore, error) {
+	s := &JSONFileStore{path: path, mem: NewMemoryFileStore()}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return s, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read file store: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.mem.records); err != nil {
+		return nil, fmt.Errorf("failed to parse file store %q: %w", path, err)
+	}
+	if s.mem.records == nil {
+		s.mem.records = make(map[string]FileRecord)
+	}
+	return s, nil
+}
+
+func (s *JSONFileStore) Get(ctx context.Context, hash string) (FileRecord, bool, error) {
+	return s.mem.Get(ctx, hash)
+}
+
+func (s *JSONFileStore) Put(ctx context.Context, rec FileRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.mem.Put(ctx, rec)
+	return s.save()
+}
+
+func (s *JSONFileStore) Delete(ctx context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.mem.Delete(ctx, hash)
+	return s.save()
+}
+
+func (s *JSONFileStore) save() error {
+	s.mem.mu.RLock()
+	data, err := json.MarshalIndent(s.mem.records, "", "  ")
+	s.mem.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write file store: %w", err)
+	}
+	return nil
+}
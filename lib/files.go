@@ -1,9 +1,17 @@
 package genai_sdk
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"google.golang.org/genai"
 )
@@ -11,10 +19,21 @@ import (
 // FileClient exposes a small surface for file management on the LLM backend.
 type FileClient interface {
 	UploadFromPath(ctx context.Context, path string, cfg *genai.UploadFileConfig) (*genai.File, error)
+	Upload(ctx context.Context, r io.Reader, mimeType, displayName string) (*genai.File, error)
+	Get(ctx context.Context, name string) (*genai.File, error)
+	Delete(ctx context.Context, name string) error
+	WaitUntilActive(ctx context.Context, name string) (*genai.File, error)
 	List(ctx context.Context) ([]*genai.File, error)
+	ListFiles(ctx context.Context, filter FileFilter) iter.Seq2[*genai.File, error]
 	Download(ctx context.Context, file *genai.File, cfg *genai.DownloadFileConfig) ([]byte, error)
+	DownloadTo(ctx context.Context, file *genai.File, w io.Writer) (int64, error)
 }
 
+const (
+	defaultFilePollInterval = time.Second
+	defaultFileMaxPollDelay = 10 * time.Second
+)
+
 // UploadFromPath uploads a local file to the LLM provider.
 func (ai *GeminiChatClient) UploadFromPath(ctx context.Context, path string, cfg *genai.UploadFileConfig) (*genai.File, error) {
 	if ai.client == nil {
@@ -26,15 +45,184 @@ func (ai *GeminiChatClient) UploadFromPath(ctx context.Context, path string, cfg
 	return ai.client.Files.UploadFromPath(ctx, path, cfg)
 }
 
-// List returns all files currently known to the provider.
-func (ai *GeminiChatClient) List(ctx context.Context) ([]*genai.File, error) {
+// Upload streams r to the provider. The returned file may still be
+// PROCESSING; use WaitUntilActive before referencing it in a prompt.
+func (ai *GeminiChatClient) Upload(ctx context.Context, r io.Reader, mimeType, displayName string) (*genai.File, error) {
+	if ai.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	if r == nil {
+		return nil, fmt.Errorf("reader is nil")
+	}
+	if mimeType == "" {
+		return nil, fmt.Errorf("MIME type is required when uploading from a reader")
+	}
+	file, err := ai.client.Files.Upload(ctx, r, &genai.UploadFileConfig{MIMEType: mimeType, DisplayName: displayName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", ClassifyError(err))
+	}
+	return file, nil
+}
+
+// Get fetches the current metadata of a file, e.g. "files/abc-123".
+func (ai *GeminiChatClient) Get(ctx context.Context, name string) (*genai.File, error) {
 	if ai.client == nil {
 		return nil, fmt.Errorf("client not initialized")
 	}
+	if name == "" {
+		return nil, fmt.Errorf("file name is required")
+	}
+	file, err := ai.client.Files.Get(ctx, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file %s: %w", name, ClassifyError(err))
+	}
+	return file, nil
+}
 
-	iter := ai.client.Files.All(ctx)
+// Delete removes a file from the provider.
+func (ai *GeminiChatClient) Delete(ctx context.Context, name string) error {
+	if ai.client == nil {
+		return fmt.Errorf("client not initialized")
+	}
+	if name == "" {
+		return fmt.Errorf("file name is required")
+	}
+	if _, err := ai.client.Files.Delete(ctx, name, nil); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", name, ClassifyError(err))
+	}
+	return nil
+}
+
+// WithFilePollInterval sets the initial and maximum delay between state polls
+// in WaitUntilActive. The delay doubles after each poll up to max.
+func (ai *GeminiChatClient) WithFilePollInterval(initial, max time.Duration) *GeminiChatClient {
+	if initial > 0 {
+		ai.filePollInterval = initial
+	}
+	if max >= ai.filePollInterval {
+		ai.fileMaxPollDelay = max
+	}
+	return ai
+}
+
+// WaitUntilActive polls the file with exponential backoff until it leaves the
+// PROCESSING state. A file that ends FAILED is returned together with a
+// *FileProcessingError.
+func (ai *GeminiChatClient) WaitUntilActive(ctx context.Context, name string) (*genai.File, error) {
+	initial, maxDelay := ai.filePollDelays()
+	return waitFileActive(ctx, ai.logger, name, ai.Get, initial, maxDelay)
+}
+
+// filePollDelays applies the defaults to the configured poll delays. The
+// maximum never drops below the initial delay.
+func (ai *GeminiChatClient) filePollDelays() (initial, maxDelay time.Duration) {
+	initial = cmp.Or(ai.filePollInterval, defaultFilePollInterval)
+	return initial, max(cmp.Or(ai.fileMaxPollDelay, defaultFileMaxPollDelay), initial)
+}
+
+func waitFileActive(ctx context.Context, logger *slog.Logger, name string,
+	get func(ctx context.Context, name string) (*genai.File, error), delay, maxDelay time.Duration,
+) (*genai.File, error) {
+	for {
+		file, err := get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		switch file.State {
+		case genai.FileStateActive:
+			return file, nil
+		case genai.FileStateFailed:
+			return file, fileProcessingErr(file)
+		}
+		logger.DebugContext(ctx, "File still processing",
+			slog.String("file", name),
+			slog.String("state", string(file.State)),
+			slog.Duration("next_poll", delay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return file, ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// FileProcessingError reports a file the provider could not process.
+type FileProcessingError struct {
+	Name    string
+	Code    int32
+	Message string
+}
+
+func (e *FileProcessingError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("file %s failed processing", e.Name)
+	}
+	return fmt.Sprintf("file %s failed processing: %s", e.Name, e.Message)
+}
+
+func fileProcessingErr(file *genai.File) error {
+	err := &FileProcessingError{Name: file.Name}
+	if file.Error != nil {
+		err.Message = file.Error.Message
+		if file.Error.Code != nil {
+			err.Code = *file.Error.Code
+		}
+	}
+	return err
+}
+
+// FileFilter selects files in ListFiles. Zero fields match every file.
+type FileFilter struct {
+	// PageSize is the number of files fetched per request; the provider
+	// default applies when zero.
+	PageSize int32
+	// State keeps files in this state.
+	State genai.FileState
+	// MIMEType keeps files of this type. A value ending in "/", such as
+	// "video/", matches the whole family.
+	MIMEType string
+	// DisplayNamePrefix keeps files whose display name starts with it.
+	DisplayNamePrefix string
+	// CreatedBefore keeps files created before this time. Files without a
+	// create time are excluded, since their age is unknown.
+	CreatedBefore time.Time
+}
+
+// Match reports whether file passes the filter.
+func (f FileFilter) Match(file *genai.File) bool {
+	if file == nil {
+		return false
+	}
+	if f.State != "" && file.State != f.State {
+		return false
+	}
+	if f.MIMEType != "" {
+		if strings.HasSuffix(f.MIMEType, "/") {
+			if !strings.HasPrefix(file.MIMEType, f.MIMEType) {
+				return false
+			}
+		} else if file.MIMEType != f.MIMEType {
+			return false
+		}
+	}
+	if !strings.HasPrefix(file.DisplayName, f.DisplayNamePrefix) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && (file.CreateTime.IsZero() || !file.CreateTime.Before(f.CreatedBefore)) {
+		return false
+	}
+	return true
+}
+
+// List returns all files currently known to the provider. Prefer ListFiles
+// for large accounts.
+func (ai *GeminiChatClient) List(ctx context.Context) ([]*genai.File, error) {
 	var files []*genai.File
-	for file, err := range iter {
+	for file, err := range ai.ListFiles(ctx, FileFilter{}) {
 		if err != nil {
 			return nil, err
 		}
@@ -43,7 +231,49 @@ func (ai *GeminiChatClient) List(ctx context.Context) ([]*genai.File, error) {
 	return files, nil
 }
 
-// Download fetches file bytes for a previously uploaded file.
+// ListFiles iterates over the provider's files matching filter, fetching one
+// page at a time as the caller consumes them.
+func (ai *GeminiChatClient) ListFiles(ctx context.Context, filter FileFilter) iter.Seq2[*genai.File, error] {
+	if ai.client == nil {
+		return func(yield func(*genai.File, error) bool) {
+			yield(nil, fmt.Errorf("client not initialized"))
+		}
+	}
+	return listFilePages(ctx, filter, func(ctx context.Context, token string) ([]*genai.File, string, error) {
+		page, err := ai.client.Files.List(ctx, &genai.ListFilesConfig{PageSize: filter.PageSize, PageToken: token})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list files: %w", ClassifyError(err))
+		}
+		return page.Items, page.NextPageToken, nil
+	})
+}
+
+func listFilePages(ctx context.Context, filter FileFilter,
+	fetch func(ctx context.Context, token string) ([]*genai.File, string, error),
+) iter.Seq2[*genai.File, error] {
+	return func(yield func(*genai.File, error) bool) {
+		token := ""
+		for {
+			files, next, err := fetch(ctx, token)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, file := range files {
+				if filter.Match(file) && !yield(file, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			token = next
+		}
+	}
+}
+
+// Download fetches file bytes for a previously uploaded file. Use DownloadTo
+// for large files.
 func (ai *GeminiChatClient) Download(ctx context.Context, file *genai.File, cfg *genai.DownloadFileConfig) ([]byte, error) {
 	if ai.client == nil {
 		return nil, fmt.Errorf("client not initialized")
@@ -54,4 +284,125 @@ func (ai *GeminiChatClient) Download(ctx context.Context, file *genai.File, cfg
 	return ai.client.Files.Download(ctx, file, cfg)
 }
 
+// DownloadTo streams the content of file to w without buffering it and
+// returns the number of bytes written. Nothing is written unless the server
+// answers with a success status. It needs a Gemini API client with an API
+// key; use Download with Vertex AI.
+func (ai *GeminiChatClient) DownloadTo(ctx context.Context, file *genai.File, w io.Writer) (int64, error) {
+	if ai.client == nil {
+		return 0, fmt.Errorf("client not initialized")
+	}
+	if file == nil {
+		return 0, fmt.Errorf("file is nil")
+	}
+	req, err := newFileDownloadRequest(ai.client)
+	if err != nil {
+		return 0, err
+	}
+	return req.do(ctx, file, w)
+}
+
+type fileDownloadRequest struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	headers http.Header
+}
+
+// newFileDownloadRequest builds a download against client's endpoint. The
+// request is made outside the SDK and authenticates with the API key only, so
+// Vertex AI and credential-based clients are rejected rather than sent
+// unauthenticated.
+func newFileDownloadRequest(client *genai.Client) (fileDownloadRequest, error) {
+	cfg := client.ClientConfig()
+	if cfg.Backend != genai.BackendGeminiAPI {
+		return fileDownloadRequest{}, fmt.Errorf("streaming file downloads are only supported with the Gemini API backend")
+	}
+	if cfg.APIKey == "" {
+		return fileDownloadRequest{}, fmt.Errorf("streaming file downloads require an API key")
+	}
+	return fileDownloadRequest{
+		client:  cmp.Or(cfg.HTTPClient, http.DefaultClient),
+		baseURL: strings.TrimSuffix(cfg.HTTPOptions.BaseURL, "/") + "/" + cmp.Or(cfg.HTTPOptions.APIVersion, "v1beta"),
+		apiKey:  cfg.APIKey,
+		headers: cfg.HTTPOptions.Headers,
+	}, nil
+}
+
+func (d fileDownloadRequest) do(ctx context.Context, file *genai.File, w io.Writer) (int64, error) {
+	body, err := d.open(ctx, file.Name)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	n, err := io.Copy(w, body)
+	if err != nil {
+		return n, fmt.Errorf("failed to download file %s: %w", file.Name, err)
+	}
+	return n, nil
+}
+
+// open starts the download of the named file and returns its body once the
+// server has answered with a success status.
+func (d fileDownloadRequest) open(ctx context.Context, name string) (io.ReadCloser, error) {
+	id := strings.TrimPrefix(name, "files/")
+	if id == "" {
+		return nil, fmt.Errorf("file name is required")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/files/"+id+":download?alt=media", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build download request: %w", err)
+	}
+	for k, v := range d.headers {
+		req.Header[k] = v
+	}
+	if d.apiKey != "" {
+		req.Header.Set("x-goog-api-key", d.apiKey)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file %s: %w", name, ClassifyError(err))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		apiErr := genai.APIError{Code: resp.StatusCode, Status: resp.Status, Message: strings.TrimSpace(string(body))}
+		return nil, fmt.Errorf("failed to download file %s: %w", name, ClassifyError(apiErr))
+	}
+	return resp.Body, nil
+}
+
+// DeleteFilesOlderThan deletes every file matching filter that was created
+// more than age ago and returns the names it deleted. Matching files are
+// listed before any is deleted, so pagination is not disturbed; a failed
+// delete does not stop the others and is reported in the joined error.
+func DeleteFilesOlderThan(ctx context.Context, files FileClient, age time.Duration, filter FileFilter) ([]string, error) {
+	cutoff := time.Now().Add(-age)
+	if filter.CreatedBefore.IsZero() || filter.CreatedBefore.After(cutoff) {
+		filter.CreatedBefore = cutoff
+	}
+	var names []string
+	for file, err := range files.ListFiles(ctx, filter) {
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, file.Name)
+	}
+
+	var deleted []string
+	var errs []error
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := files.Delete(ctx, name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deleted = append(deleted, name)
+	}
+	return deleted, errors.Join(errs...)
+}
+
 var _ FileClient = (*GeminiChatClient)(nil)
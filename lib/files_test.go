@@ -0,0 +1,484 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestWaitFileActive(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name    string
+		states  []genai.FileState
+		wantErr bool
+		polls   int
+	}{
+		{"already active", []genai.FileState{genai.FileStateActive}, false, 1},
+		{"processing then active", []genai.FileState{genai.FileStateProcessing, genai.FileStateProcessing, genai.FileStateActive}, false, 3},
+		{"processing then failed", []genai.FileState{genai.FileStateProcessing, genai.FileStateFailed}, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls := 0
+			get := func(_ context.Context, name string) (*genai.File, error) {
+				state := tt.states[polls]
+				polls++
+				file := &genai.File{Name: name, State: state}
+				if state == genai.FileStateFailed {
+					file.Error = &genai.FileStatus{Message: "unsupported codec", Code: genai.Ptr[int32](3)}
+				}
+				return file, nil
+			}
+			file, err := waitFileActive(context.Background(), logger, "files/video", get, time.Millisecond, 2*time.Millisecond)
+			if polls != tt.polls {
+				t.Errorf("polls = %d, want %d", polls, tt.polls)
+			}
+			if !tt.wantErr {
+				if err != nil || file.State != genai.FileStateActive {
+					t.Errorf("got %v, %v", file, err)
+				}
+				return
+			}
+			var procErr *FileProcessingError
+			if !errors.As(err, &procErr) || procErr.Code != 3 || !strings.Contains(err.Error(), "unsupported codec") {
+				t.Errorf("expected *FileProcessingError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWaitFileActive_ContextCancelled(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	get := func(_ context.Context, name string) (*genai.File, error) {
+		cancel()
+		return &genai.File{Name: name, State: genai.FileStateProcessing}, nil
+	}
+	if _, err := waitFileActive(ctx, logger, "files/video", get, time.Hour, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFilePollDelays(t *testing.T) {
+	tests := []struct {
+		name             string
+		initial, maxPoll time.Duration
+		want             [2]time.Duration
+	}{
+		{"defaults", 0, 0, [2]time.Duration{time.Second, 10 * time.Second}},
+		{"small max", 100 * time.Millisecond, 200 * time.Millisecond, [2]time.Duration{100 * time.Millisecond, 200 * time.Millisecond}},
+		{"initial above default max", 20 * time.Second, 0, [2]time.Duration{20 * time.Second, 20 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := (&GeminiChatClient{}).WithFilePollInterval(tt.initial, tt.maxPoll)
+			if initial, maxDelay := ai.filePollDelays(); initial != tt.want[0] || maxDelay != tt.want[1] {
+				t.Errorf("filePollDelays() = %v, %v, want %v", initial, maxDelay, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpload_Validation(t *testing.T) {
+	client, err := NewGeminiChatClient(context.Background(), "test-api-key", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files := client.(*GeminiChatClient)
+	if _, err := files.Upload(context.Background(), strings.NewReader("data"), "", "notes"); err == nil {
+		t.Error("expected error without MIME type")
+	}
+	if _, err := files.Get(context.Background(), ""); err == nil {
+		t.Error("expected error without file name")
+	}
+	if err := files.Delete(context.Background(), ""); err == nil {
+		t.Error("expected error without file name")
+	}
+}
+
+// fakeFileClient is an in-memory FileClient.
+type fakeFileClient struct {
+	mu      sync.Mutex
+	files   map[string]*genai.File
+	uploads int
+	getErr  error
+	// waitErr, when set, is returned by WaitUntilActive as if processing
+	// had failed.
+	waitErr error
+}
+
+func newFakeFileClient() *fakeFileClient {
+	return &fakeFileClient{files: make(map[string]*genai.File)}
+}
+
+func (c *fakeFileClient) UploadFromPath(ctx context.Context, path string, cfg *genai.UploadFileConfig) (*genai.File, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeFileClient) Upload(_ context.Context, r io.Reader, mimeType, displayName string) (*genai.File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.uploads++
+	name := fmt.Sprintf("files/%d", c.uploads)
+	file := &genai.File{
+		Name:        name,
+		URI:         "https://example.test/" + name,
+		MIMEType:    mimeType,
+		DisplayName: displayName,
+		SizeBytes:   genai.Ptr(int64(len(data))),
+		State:       genai.FileStateActive,
+	}
+	c.files[name] = file
+	return file, nil
+}
+
+func (c *fakeFileClient) Get(_ context.Context, name string) (*genai.File, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.getErr != nil {
+		return nil, c.getErr
+	}
+	file, ok := c.files[name]
+	if !ok {
+		return nil, &Error{Kind: ErrNotFound, Code: 404}
+	}
+	return file, nil
+}
+
+func (c *fakeFileClient) Delete(_ context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.files, name)
+	return nil
+}
+
+func (c *fakeFileClient) WaitUntilActive(ctx context.Context, name string) (*genai.File, error) {
+	if c.waitErr != nil {
+		return nil, c.waitErr
+	}
+	return c.Get(ctx, name)
+}
+
+func (c *fakeFileClient) List(context.Context) ([]*genai.File, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var files []*genai.File
+	for _, f := range c.files {
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (c *fakeFileClient) ListFiles(_ context.Context, filter FileFilter) iter.Seq2[*genai.File, error] {
+	c.mu.Lock()
+	files := slices.SortedFunc(maps.Values(c.files), func(a, b *genai.File) int { return strings.Compare(a.Name, b.Name) })
+	c.mu.Unlock()
+	return func(yield func(*genai.File, error) bool) {
+		for _, f := range files {
+			if filter.Match(f) && !yield(f, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (c *fakeFileClient) Download(context.Context, *genai.File, *genai.DownloadFileConfig) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeFileClient) DownloadTo(context.Context, *genai.File, io.Writer) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func TestFileRegistry_Dedupes(t *testing.T) {
+	files := newFakeFileClient()
+	reg := NewFileRegistry(files, nil)
+	ctx := context.Background()
+
+	first, err := reg.Upload(ctx, strings.NewReader("guidebook"), "application/pdf", "guide.pdf")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	// A non-seekable reader with the same content hits the registry too.
+	second, err := reg.Upload(ctx, io.MultiReader(strings.NewReader("guide"), strings.NewReader("book")), "application/pdf", "copy.pdf")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if files.uploads != 1 || first.Name != second.Name {
+		t.Errorf("uploads = %d, names %s/%s; want one shared upload", files.uploads, first.Name, second.Name)
+	}
+
+	if _, err := reg.Upload(ctx, strings.NewReader("other"), "application/pdf", ""); err != nil || files.uploads != 2 {
+		t.Errorf("different content should upload: uploads=%d err=%v", files.uploads, err)
+	}
+	if _, err := reg.Upload(ctx, strings.NewReader("guidebook"), "text/plain", ""); err != nil || files.uploads != 3 {
+		t.Errorf("same content as another MIME type should upload: uploads=%d err=%v", files.uploads, err)
+	}
+	if len(reg.locks) != 0 {
+		t.Errorf("%d upload locks left after uploads finished", len(reg.locks))
+	}
+}
+
+func TestFileRegistry_ReuploadsExpiredOrMissing(t *testing.T) {
+	files := newFakeFileClient()
+	now := time.Unix(1_700_000_000, 0)
+	reg := NewFileRegistry(files, nil).WithExpiryMargin(time.Hour)
+	reg.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := reg.Upload(ctx, strings.NewReader("pdf"), "application/pdf", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(FileTTL - 30*time.Minute) // inside the margin
+	if _, err := reg.Upload(ctx, strings.NewReader("pdf"), "application/pdf", ""); err != nil || files.uploads != 2 {
+		t.Errorf("expiring file should be re-uploaded: uploads=%d err=%v", files.uploads, err)
+	}
+
+	_ = files.Delete(ctx, "files/2")
+	if _, err := reg.Upload(ctx, strings.NewReader("pdf"), "application/pdf", ""); err != nil || files.uploads != 3 {
+		t.Errorf("deleted file should be re-uploaded: uploads=%d err=%v", files.uploads, err)
+	}
+}
+
+func TestFileRegistry_KeepsRecordOnTransientError(t *testing.T) {
+	files := newFakeFileClient()
+	reg := NewFileRegistry(files, nil)
+	ctx := context.Background()
+
+	if _, err := reg.Upload(ctx, strings.NewReader("pdf"), "application/pdf", ""); err != nil {
+		t.Fatal(err)
+	}
+	files.getErr = &Error{Kind: ErrServerError, Code: 503}
+	if _, err := reg.Upload(ctx, strings.NewReader("pdf"), "application/pdf", ""); !errors.Is(err, ErrServerError) {
+		t.Fatalf("Upload = %v, want the server error", err)
+	}
+
+	files.getErr = nil
+	if _, err := reg.Upload(ctx, strings.NewReader("pdf"), "application/pdf", ""); err != nil || files.uploads != 1 {
+		t.Errorf("record should survive a transient error: uploads=%d err=%v", files.uploads, err)
+	}
+}
+
+func TestFileRegistry_DeletesFileThatFailsProcessing(t *testing.T) {
+	files := newFakeFileClient()
+	files.waitErr = errors.New("file files/1 failed processing")
+	reg := NewFileRegistry(files, nil)
+
+	if _, err := reg.Upload(context.Background(), strings.NewReader("pdf"), "application/pdf", ""); err == nil {
+		t.Fatal("expected the processing error")
+	}
+	if len(files.files) != 0 {
+		t.Errorf("%d files left remotely after failed processing, want 0", len(files.files))
+	}
+}
+
+func TestJSONFileStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.json")
+	ctx := context.Background()
+
+	store, err := NewJSONFileStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONFileStore: %v", err)
+	}
+	rec := FileRecord{Hash: "abc", Name: "files/1", URI: "uri", ExpiresAt: time.Unix(1_700_000_000, 0).UTC()}
+	if err := store.Put(ctx, rec); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	reopened, err := NewJSONFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	got, ok, err := reopened.Get(ctx, "abc")
+	if err != nil || !ok || got != rec {
+		t.Errorf("Get = %+v, %v, %v; want %+v", got, ok, err, rec)
+	}
+
+	if err := reopened.Delete(ctx, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	again, _ := NewJSONFileStore(path)
+	if _, ok, _ := again.Get(ctx, "abc"); ok {
+		t.Error("deleted record persisted")
+	}
+}
+
+func TestFileFilter_Match(t *testing.T) {
+	created := time.Unix(1_700_000_000, 0)
+	file := &genai.File{
+		Name:        "files/1",
+		DisplayName: "tour-belem.mp4",
+		MIMEType:    "video/mp4",
+		State:       genai.FileStateActive,
+		CreateTime:  created,
+	}
+	tests := []struct {
+		name   string
+		filter FileFilter
+		want   bool
+	}{
+		{"zero filter", FileFilter{}, true},
+		{"state", FileFilter{State: genai.FileStateActive}, true},
+		{"other state", FileFilter{State: genai.FileStateFailed}, false},
+		{"exact MIME type", FileFilter{MIMEType: "video/mp4"}, true},
+		{"MIME family", FileFilter{MIMEType: "video/"}, true},
+		{"other MIME family", FileFilter{MIMEType: "audio/"}, false},
+		{"display name prefix", FileFilter{DisplayNamePrefix: "tour-"}, true},
+		{"other prefix", FileFilter{DisplayNamePrefix: "guide-"}, false},
+		{"created before", FileFilter{CreatedBefore: created.Add(time.Second)}, true},
+		{"created after", FileFilter{CreatedBefore: created}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(file); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	undated := &genai.File{Name: "files/2"}
+	if (FileFilter{CreatedBefore: created}).Match(undated) {
+		t.Error("file without a create time matched CreatedBefore")
+	}
+	if !(FileFilter{}).Match(undated) {
+		t.Error("file without a create time should match a filter without CreatedBefore")
+	}
+}
+
+func TestListFilePages(t *testing.T) {
+	pages := map[string]struct {
+		files []*genai.File
+		next  string
+	}{
+		"":   {[]*genai.File{{Name: "files/a", MIMEType: "video/mp4"}, {Name: "files/b", MIMEType: "image/png"}}, "p2"},
+		"p2": {[]*genai.File{{Name: "files/c", MIMEType: "video/webm"}}, "p3"},
+		"p3": {nil, ""},
+	}
+	var tokens []string
+	fetch := func(_ context.Context, token string) ([]*genai.File, string, error) {
+		tokens = append(tokens, token)
+		page := pages[token]
+		return page.files, page.next, nil
+	}
+
+	var names []string
+	for file, err := range listFilePages(context.Background(), FileFilter{MIMEType: "video/"}, fetch) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, file.Name)
+	}
+	if !slices.Equal(names, []string{"files/a", "files/c"}) || !slices.Equal(tokens, []string{"", "p2", "p3"}) {
+		t.Errorf("names = %v, tokens = %q", names, tokens)
+	}
+
+	// Stopping early does not fetch further pages.
+	tokens = nil
+	for range listFilePages(context.Background(), FileFilter{}, fetch) {
+		break
+	}
+	if len(tokens) != 1 {
+		t.Errorf("fetched %d pages after break, want 1", len(tokens))
+	}
+}
+
+func TestListFilePages_Error(t *testing.T) {
+	fetch := func(context.Context, string) ([]*genai.File, string, error) {
+		return nil, "", errors.New("boom")
+	}
+	for file, err := range listFilePages(context.Background(), FileFilter{}, fetch) {
+		if err == nil || file != nil {
+			t.Errorf("got %v, %v; want error", file, err)
+		}
+	}
+}
+
+func TestFileDownloadRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-goog-api-key") != "test-api-key" {
+			http.Error(w, "missing key", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v1beta/files/abc:download" || r.URL.Query().Get("alt") != "media" {
+			http.Error(w, `{"error":{"code":404,"message":"not found","status":"NOT_FOUND"}}`, http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, "video bytes")
+	}))
+	defer srv.Close()
+
+	d := fileDownloadRequest{client: srv.Client(), baseURL: srv.URL + "/v1beta", apiKey: "test-api-key"}
+	var buf strings.Builder
+	n, err := d.do(context.Background(), &genai.File{Name: "files/abc"}, &buf)
+	if err != nil || n != int64(len("video bytes")) || buf.String() != "video bytes" {
+		t.Errorf("do = %d, %v, %q", n, err, buf.String())
+	}
+
+	buf.Reset()
+	_, err = d.do(context.Background(), &genai.File{Name: "files/missing"}, &buf)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("error body written to destination: %q", buf.String())
+	}
+}
+
+func TestNewFileDownloadRequest_RequiresGeminiAPIKey(t *testing.T) {
+	ctx := context.Background()
+	vertex, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: "test-api-key", Backend: genai.BackendVertexAI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newFileDownloadRequest(vertex); err == nil {
+		t.Error("expected an error for a Vertex AI client")
+	}
+
+	gemini, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: "test-api-key", Backend: genai.BackendGeminiAPI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newFileDownloadRequest(gemini); err != nil {
+		t.Errorf("unexpected error for a Gemini API client: %v", err)
+	}
+}
+
+func TestDeleteFilesOlderThan(t *testing.T) {
+	files := newFakeFileClient()
+	now := time.Now()
+	files.files["files/old-video"] = &genai.File{Name: "files/old-video", MIMEType: "video/mp4", CreateTime: now.Add(-3 * time.Hour)}
+	files.files["files/old-image"] = &genai.File{Name: "files/old-image", MIMEType: "image/png", CreateTime: now.Add(-3 * time.Hour)}
+	files.files["files/new-video"] = &genai.File{Name: "files/new-video", MIMEType: "video/mp4", CreateTime: now}
+	files.files["files/undated-video"] = &genai.File{Name: "files/undated-video", MIMEType: "video/mp4"}
+
+	deleted, err := DeleteFilesOlderThan(context.Background(), files, time.Hour, FileFilter{MIMEType: "video/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(deleted, []string{"files/old-video"}) {
+		t.Errorf("deleted = %v", deleted)
+	}
+	if _, ok := files.files["files/old-image"]; !ok || len(files.files) != 3 {
+		t.Errorf("remaining files = %v", slices.Collect(maps.Keys(files.files)))
+	}
+}
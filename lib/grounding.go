@@ -0,0 +1,219 @@
+package genai_sdk
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Grounding is the typed view of the grounding and citation metadata attached
+// to the candidate ExtractText reads.
+type Grounding struct {
+	// Sources are the grounding chunks, in the order the model indexes them.
+	Sources []GroundingSource
+	// Segments are the spans of answer text and the sources supporting them.
+	Segments []GroundedSegment
+	// SearchQueries are the Google Search queries the model issued.
+	SearchQueries []string
+	// SearchEntryPoint is the rendered HTML search widget that Google Search
+	// grounding requires to be displayed alongside the answer.
+	SearchEntryPoint string
+	// URLs lists pages fetched by the URL-context tool.
+	URLs []URLRetrieval
+	// Citations are recitation citations from CitationMetadata.
+	Citations []CitationSource
+}
+
+// GroundingSource is one retrieved reference.
+type GroundingSource struct {
+	Index int
+	// Kind is "web", "maps", "retrieved_context" or "image".
+	Kind   string
+	Title  string
+	URI    string
+	Domain string
+}
+
+// GroundedSegment is a span of the answer and the sources that support it.
+// Start and End are byte offsets into the text returned by ExtractText.
+type GroundedSegment struct {
+	Text          string
+	Start, End    int
+	SourceIndices []int
+	Confidence    []float32
+}
+
+// URLRetrieval reports the status of one URL fetched for URL context.
+type URLRetrieval struct {
+	URL    string
+	Status genai.URLRetrievalStatus
+}
+
+// CitationSource is a source the model quoted from at length.
+type CitationSource struct {
+	Title      string
+	URI        string
+	License    string
+	Start, End int
+}
+
+// ExtractGrounding returns the grounding metadata of the answer candidate.
+// It returns an empty Grounding when the response was not grounded.
+func ExtractGrounding(resp *genai.GenerateContentResponse) (*Grounding, error) {
+	cand, _, _, err := answerCandidate(resp)
+	if err != nil {
+		return nil, err
+	}
+	g := &Grounding{}
+	offsets := answerPartOffsets(cand.Content)
+
+	if gm := cand.GroundingMetadata; gm != nil {
+		for i, chunk := range gm.GroundingChunks {
+			if src, ok := groundingSource(i, chunk); ok {
+				g.Sources = append(g.Sources, src)
+			}
+		}
+		for _, support := range gm.GroundingSupports {
+			if seg, ok := groundedSegment(support, offsets); ok {
+				g.Segments = append(g.Segments, seg)
+			}
+		}
+		g.SearchQueries = gm.WebSearchQueries
+		if gm.SearchEntryPoint != nil {
+			g.SearchEntryPoint = gm.SearchEntryPoint.RenderedContent
+		}
+	}
+	if um := cand.URLContextMetadata; um != nil {
+		for _, m := range um.URLMetadata {
+			if m != nil {
+				g.URLs = append(g.URLs, URLRetrieval{URL: m.RetrievedURL, Status: m.URLRetrievalStatus})
+			}
+		}
+	}
+	if cm := cand.CitationMetadata; cm != nil {
+		for _, c := range cm.Citations {
+			if c != nil {
+				g.Citations = append(g.Citations, CitationSource{
+					Title:   c.Title,
+					URI:     c.URI,
+					License: c.License,
+					Start:   int(c.StartIndex),
+					End:     int(c.EndIndex),
+				})
+			}
+		}
+	}
+	return g, nil
+}
+
+// RenderCitations returns the answer text with numbered markers such as
+// "[1][3]" inserted after each grounded segment. Marker numbers are 1-based
+// source indices, matching Grounding.Sources[n-1].
+func RenderCitations(resp *genai.GenerateContentResponse) (string, error) {
+	_, _, text, err := answerCandidate(resp)
+	if err != nil {
+		return "", err
+	}
+	g, err := ExtractGrounding(resp)
+	if err != nil {
+		return "", err
+	}
+
+	type insertion struct {
+		at     int
+		marker string
+	}
+	var inserts []insertion
+	for _, seg := range g.Segments {
+		if len(seg.SourceIndices) == 0 {
+			continue
+		}
+		at := seg.End
+		if at <= 0 || at > len(text) {
+			// Some responses omit offsets; fall back to locating the text.
+			idx := strings.Index(text, seg.Text)
+			if seg.Text == "" || idx < 0 {
+				continue
+			}
+			at = idx + len(seg.Text)
+		}
+		var b strings.Builder
+		for _, src := range seg.SourceIndices {
+			fmt.Fprintf(&b, "[%d]", src+1)
+		}
+		inserts = append(inserts, insertion{at: at, marker: b.String()})
+	}
+
+	// Insert from the end so earlier offsets stay valid.
+	slices.SortStableFunc(inserts, func(a, b insertion) int { return b.at - a.at })
+	for _, ins := range inserts {
+		text = text[:ins.at] + ins.marker + text[ins.at:]
+	}
+	return text, nil
+}
+
+func groundingSource(i int, chunk *genai.GroundingChunk) (GroundingSource, bool) {
+	if chunk == nil {
+		return GroundingSource{}, false
+	}
+	src := GroundingSource{Index: i}
+	switch {
+	case chunk.Web != nil:
+		src.Kind, src.Title, src.URI, src.Domain = "web", chunk.Web.Title, chunk.Web.URI, chunk.Web.Domain
+	case chunk.Maps != nil:
+		src.Kind, src.Title, src.URI = "maps", chunk.Maps.Title, chunk.Maps.URI
+	case chunk.RetrievedContext != nil:
+		src.Kind, src.Title, src.URI = "retrieved_context", chunk.RetrievedContext.Title, chunk.RetrievedContext.URI
+	case chunk.Image != nil:
+		src.Kind, src.Title, src.URI, src.Domain = "image", chunk.Image.Title, chunk.Image.SourceURI, chunk.Image.Domain
+	default:
+		return GroundingSource{}, false
+	}
+	return src, true
+}
+
+// groundedSegment converts a support into answer-text offsets. The API omits
+// partIndex when it is zero, so a zero index that doesn't name an answer part
+// (e.g. part 0 is a thought) is taken as relative to the whole answer text.
+func groundedSegment(support *genai.GroundingSupport, offsets map[int]int) (GroundedSegment, bool) {
+	if support == nil || support.Segment == nil {
+		return GroundedSegment{}, false
+	}
+	base, ok := offsets[int(support.Segment.PartIndex)]
+	if !ok && support.Segment.PartIndex != 0 {
+		return GroundedSegment{}, false
+	}
+	seg := GroundedSegment{
+		Text:       support.Segment.Text,
+		Start:      base + int(support.Segment.StartIndex),
+		End:        base + int(support.Segment.EndIndex),
+		Confidence: support.ConfidenceScores,
+	}
+	if support.Segment.EndIndex == 0 {
+		seg.End = 0
+	}
+	for _, idx := range support.GroundingChunkIndices {
+		seg.SourceIndices = append(seg.SourceIndices, int(idx))
+	}
+	return seg, true
+}
+
+// answerPartOffsets maps each answer part's index to its byte offset within
+// the concatenated answer text, skipping thought parts as ExtractText does.
+func answerPartOffsets(content *genai.Content) map[int]int {
+	offsets := map[int]int{}
+	if content == nil {
+		return offsets
+	}
+	pos := 0
+	for i, part := range content.Parts {
+		if part == nil || part.Thought || part.Text == "" {
+			continue
+		}
+		offsets[i] = pos
+		pos += len(part.Text)
+	}
+	return offsets
+}
@@ -0,0 +1,113 @@
+package genai_sdk
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func groundedResponse() *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: &genai.Content{Parts: []*genai.Part{
+				{Text: "thinking", Thought: true},
+				{Text: "Belém Tower opened in 1519. It is a UNESCO site."},
+			}},
+			GroundingMetadata: &genai.GroundingMetadata{
+				WebSearchQueries: []string{"belem tower history"},
+				SearchEntryPoint: &genai.SearchEntryPoint{RenderedContent: "<div>search</div>"},
+				GroundingChunks: []*genai.GroundingChunk{
+					{Web: &genai.GroundingChunkWeb{Title: "wikipedia.org", URI: "https://example.com/a"}},
+					{Web: &genai.GroundingChunkWeb{Title: "unesco.org", URI: "https://example.com/b"}},
+				},
+				GroundingSupports: []*genai.GroundingSupport{
+					{
+						Segment:               &genai.Segment{PartIndex: 1, EndIndex: 28, Text: "Belém Tower opened in 1519."},
+						GroundingChunkIndices: []int32{0},
+					},
+					{
+						Segment:               &genai.Segment{PartIndex: 1, StartIndex: 29, EndIndex: 50, Text: "It is a UNESCO site."},
+						GroundingChunkIndices: []int32{0, 1},
+					},
+				},
+			},
+		}},
+	}
+}
+
+func TestExtractGrounding(t *testing.T) {
+	g, err := ExtractGrounding(groundedResponse())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Sources) != 2 || g.Sources[1].Kind != "web" || g.Sources[1].URI != "https://example.com/b" {
+		t.Errorf("unexpected sources: %+v", g.Sources)
+	}
+	if len(g.Segments) != 2 || len(g.Segments[1].SourceIndices) != 2 {
+		t.Errorf("unexpected segments: %+v", g.Segments)
+	}
+	if len(g.SearchQueries) != 1 || g.SearchEntryPoint == "" {
+		t.Errorf("search metadata missing: %+v", g)
+	}
+}
+
+func TestExtractGrounding_Ungrounded(t *testing.T) {
+	resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		Content: &genai.Content{Parts: []*genai.Part{{Text: "hi"}}},
+	}}}
+	g, err := ExtractGrounding(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Sources) != 0 || len(g.Segments) != 0 {
+		t.Errorf("expected empty grounding, got %+v", g)
+	}
+}
+
+func TestRenderCitations(t *testing.T) {
+	got, err := RenderCitations(groundedResponse())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Belém Tower opened in 1519.[1] It is a UNESCO site.[1][2]"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderCitations_MissingOffsets(t *testing.T) {
+	resp := groundedResponse()
+	for _, s := range resp.Candidates[0].GroundingMetadata.GroundingSupports {
+		s.Segment.StartIndex, s.Segment.EndIndex = 0, 0
+	}
+	got, err := RenderCitations(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Belém Tower opened in 1519.[1] It is a UNESCO site.[1][2]"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderCitations_OmittedPartIndex(t *testing.T) {
+	resp := groundedResponse()
+	for _, s := range resp.Candidates[0].GroundingMetadata.GroundingSupports {
+		s.Segment.PartIndex = 0
+	}
+	g, err := ExtractGrounding(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Segments) != 2 || g.Segments[1].Start != 29 || g.Segments[1].End != 50 {
+		t.Fatalf("unexpected segments: %+v", g.Segments)
+	}
+	got, err := RenderCitations(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Belém Tower opened in 1519.[1] It is a UNESCO site.[1][2]"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// HedgePolicy enables hedged requests for Generate: when the primary request
+// has not answered within Delay, an identical request is fired and the first
+// successful response wins. The slower request is cancelled. With a bulkhead
+// set, the hedge request needs a slot of its own and is skipped when none is
+// free.
+type HedgePolicy struct {
+	// Delay before the hedge request fires. Zero disables hedging.
+	Delay time.Duration
+	// Model for the hedge request; empty reuses the client's model.
+	Model string
+	// OnComplete is called once per request that was sent, including the
+	// cancelled loser, so that usage from both can be accounted for. It runs
+	// on the request's goroutine and may be called after Generate returns.
+	OnComplete func(ctx context.Context, outcome HedgeOutcome)
+}
+
+// HedgeOutcome reports how a single request of a hedged call finished.
+type HedgeOutcome struct {
+	Model string
+	// Hedge is true for the second, delayed request.
+	Hedge bool
+	// Won is true for the request whose response was returned.
+	Won     bool
+	Latency time.Duration
+	// Usage is the token usage reported by the response, if any. A loser
+	// cancelled before the server answered has no usage to report.
+	Usage *genai.GenerateContentResponseUsageMetadata
+	Err   error
+}
+
+// WithHedging enables hedged requests for Generate and GenerateText.
+func (g *GeminiChatClient) WithHedging(policy HedgePolicy) *GeminiChatClient {
+	g.hedgePolicy = policy
+	return g
+}
+
+// generateHedged sends contents with config, which withCachedContext already
+// resolved for the client's model. A hedge leg on another model cannot use
+// that cached content and resolves prompt and original against its own model.
+func (g *GeminiChatClient) generateHedged(ctx context.Context, contents []*genai.Content, config *genai.GenerateContentConfig,
+	prompt []*genai.Content, original *genai.GenerateContentConfig,
+) (*genai.GenerateContentResponse, error) {
+	models := [2]string{g.model, g.model}
+	if g.hedgePolicy.Model != "" {
+		models[1] = g.hedgePolicy.Model
+	}
+
+	var legs [2]func(ctx context.Context) (*genai.GenerateContentResponse, error)
+	for i, model := range models {
+		legs[i] = func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+			// The primary runs in the caller's bulkhead slot; the hedge must
+			// not exceed the pool, so it only runs if a slot is free.
+			if i == 1 && g.bulkhead != nil {
+				release, ok := g.bulkhead.tryAcquire(priorityFrom(ctx, PriorityInteractive))
+				if !ok {
+					return nil, errHedgeSkipped
+				}
+				defer release()
+			}
+			contents, config := contents, config
+			if model != g.model {
+				contents, config = g.withCachedContextFor(ctx, model, prompt, original)
+			}
+			return g.client.Models.GenerateContent(ctx, model, contents, config)
+		}
+	}
+
+	onComplete := g.hedgePolicy.OnComplete
+	return hedge(ctx, g.hedgePolicy.Delay, g.retryPolicy.clock(), legs,
+		func(leg int, resp *genai.GenerateContentResponse, err error, won bool, latency time.Duration) {
+			if onComplete == nil || errors.Is(err, errHedgeSkipped) {
+				return
+			}
+			outcome := HedgeOutcome{
+				Model:   models[leg],
+				Hedge:   leg == 1,
+				Won:     won,
+				Latency: latency,
+				Err:     err,
+			}
+			if resp != nil {
+				outcome.Usage = resp.UsageMetadata
+			}
+			onComplete(ctx, outcome)
+		})
+}
+
+// errHedgeSkipped is returned by a hedge leg that chose not to send its
+// request. hedge never reports it as the call's error.
+var errHedgeSkipped = errors.New("hedge request skipped")
+
+// hedge runs legs[0] and, if it has not succeeded within delay, legs[1] as
+// well. The first success is returned and the other leg is cancelled. If the
+// primary fails before the hedge fires, its error is returned without hedging
+// so the surrounding retry loop can apply its own policy. done is invoked from
+// each leg's goroutine once that leg returns.
+func hedge[T any](
+	ctx context.Context,
+	delay time.Duration,
+	clock Clock,
+	legs [2]func(ctx context.Context) (T, error),
+	done func(leg int, result T, err error, won bool, latency time.Duration),
+) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type legResult struct {
+		value T
+		err   error
+		won   bool
+	}
+	results := make(chan legResult, len(legs))
+	var winner atomic.Int32
+	winner.Store(-1)
+
+	launch := func(leg int) {
+		start := clock.Now()
+		go func() {
+			value, err := legs[leg](ctx)
+			won := err == nil && winner.CompareAndSwap(-1, int32(leg))
+			if done != nil {
+				done(leg, value, err, won, clock.Now().Sub(start))
+			}
+			results <- legResult{value: value, err: err, won: won}
+		}()
+	}
+
+	launch(0)
+	launched, pending := 1, 1
+	timer := clock.After(delay)
+	var zero T
+	var firstErr error
+
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-timer:
+			timer = nil
+			if pending > 0 && launched < len(legs) {
+				launch(launched)
+				launched++
+				pending++
+			}
+		case r := <-results:
+			pending--
+			if r.won {
+				return r.value, nil
+			}
+			if r.err != nil && firstErr == nil && !errors.Is(r.err, errHedgeSkipped) {
+				firstErr = r.err
+			}
+			if pending == 0 {
+				return zero, firstErr
+			}
+		}
+	}
+}
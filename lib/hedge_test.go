@@ -0,0 +1,145 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestHedge_PrimaryFastNoHedge(t *testing.T) {
+	var hedged bool
+	legs := [2]func(context.Context) (string, error){
+		func(context.Context) (string, error) { return "primary", nil },
+		func(context.Context) (string, error) { hedged = true; return "hedge", nil },
+	}
+	got, err := hedge(context.Background(), 50*time.Millisecond, realClock{}, legs, nil)
+	if err != nil || got != "primary" {
+		t.Fatalf("got %q, %v; want primary", got, err)
+	}
+	if hedged {
+		t.Error("hedge request should not fire when primary answers before the delay")
+	}
+}
+
+func TestHedge_SlowPrimaryLosesAndIsCancelled(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	var mu sync.Mutex
+	outcomes := map[int]bool{}
+	var primaryErr error
+
+	legs := [2]func(context.Context) (string, error){
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+		func(context.Context) (string, error) { return "hedge", nil },
+	}
+	got, err := hedge(context.Background(), 5*time.Millisecond, realClock{}, legs,
+		func(leg int, _ string, err error, won bool, _ time.Duration) {
+			defer wg.Done()
+			mu.Lock()
+			defer mu.Unlock()
+			outcomes[leg] = won
+			if leg == 0 {
+				primaryErr = err
+			}
+		})
+	if err != nil || got != "hedge" {
+		t.Fatalf("got %q, %v; want hedge", got, err)
+	}
+
+	wg.Wait()
+	if outcomes[0] || !outcomes[1] {
+		t.Errorf("outcomes = %v, want hedge leg to win", outcomes)
+	}
+	if !errors.Is(primaryErr, context.Canceled) {
+		t.Errorf("losing primary should be cancelled, got %v", primaryErr)
+	}
+}
+
+func TestHedge_FirstFailureWaitsForOther(t *testing.T) {
+	release := make(chan struct{})
+	legs := [2]func(context.Context) (string, error){
+		func(context.Context) (string, error) {
+			<-release
+			return "primary", nil
+		},
+		func(context.Context) (string, error) {
+			defer close(release)
+			return "", errors.New("hedge failed")
+		},
+	}
+	got, err := hedge(context.Background(), time.Millisecond, realClock{}, legs, nil)
+	if err != nil || got != "primary" {
+		t.Fatalf("got %q, %v; want primary after hedge failure", got, err)
+	}
+}
+
+func TestHedge_PrimaryFailsBeforeDelay(t *testing.T) {
+	want := errors.New("boom")
+	legs := [2]func(context.Context) (string, error){
+		func(context.Context) (string, error) { return "", want },
+		func(context.Context) (string, error) { return "hedge", nil },
+	}
+	_, err := hedge(context.Background(), time.Hour, realClock{}, legs, nil)
+	if !errors.Is(err, want) {
+		t.Errorf("expected primary error, got %v", err)
+	}
+}
+
+func TestHedge_SkippedLegDoesNotMaskPrimaryError(t *testing.T) {
+	want := errors.New("boom")
+	legs := [2]func(context.Context) (string, error){
+		func(context.Context) (string, error) {
+			time.Sleep(10 * time.Millisecond)
+			return "", want
+		},
+		func(context.Context) (string, error) { return "", errHedgeSkipped },
+	}
+	_, err := hedge(context.Background(), time.Millisecond, realClock{}, legs, nil)
+	if !errors.Is(err, want) {
+		t.Errorf("expected primary error, got %v", err)
+	}
+}
+
+func TestGenerate_HedgeNeedsBulkheadSlot(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		_, _ = io.WriteString(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]}}]}`)
+	}))
+	defer srv.Close()
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-api-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, _ := NewBulkhead("gemini", 1, 0)
+	g := (&GeminiChatClient{client: client, model: "gemini-2.5-flash", logger: slog.New(slog.NewTextHandler(io.Discard, nil))}).
+		WithBulkhead(pool).
+		WithHedging(HedgePolicy{Delay: time.Millisecond})
+
+	if text, err := g.GenerateText(context.Background(), "hi", nil); err != nil || text != "ok" {
+		t.Fatalf("GenerateText = %q, %v", text, err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("sent %d requests with a one-slot bulkhead, want 1", n)
+	}
+	if inUse := pool.Stats().InUse; inUse != 0 {
+		t.Errorf("%d slots still in use", inUse)
+	}
+}
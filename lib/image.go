@@ -0,0 +1,251 @@
+package genai_sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// ImageModel is the default image generation model.
+const ImageModel = "imagen-4.0-generate-001"
+
+// ImageClient abstracts image generation for illustration pipelines.
+type ImageClient interface {
+	GenerateImages(ctx context.Context, req ImageRequest) ([]GeneratedImage, error)
+	Model() string
+	Close() error
+}
+
+// ImageRequest describes the images to generate.
+type ImageRequest struct {
+	Prompt string
+	// NegativePrompt describes what to keep out of the image.
+	NegativePrompt string
+	// AspectRatio is one of "1:1", "3:4", "4:3", "9:16" or "16:9".
+	AspectRatio string
+	// Count is the number of images; defaults to 1.
+	Count int
+	// SafetyFilterLevel applies to Imagen models only.
+	SafetyFilterLevel genai.SafetyFilterLevel
+}
+
+// GeneratedImage is a decoded image returned by the model.
+type GeneratedImage struct {
+	Data     []byte
+	MIMEType string
+}
+
+// GeminiImageClient generates images with Imagen models through
+// Models.GenerateImages, or with Gemini native image models through
+// GenerateContent with an IMAGE response modality.
+type GeminiImageClient struct {
+	client      *genai.Client
+	model       string
+	retryPolicy RetryPolicy
+	logger      *slog.Logger
+}
+
+// NewGeminiImageClient creates an ImageClient. modelName defaults to
+// ImageModel when empty.
+func NewGeminiImageClient(ctx context.Context, apiKey, modelName string) (ImageClient, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if modelName == "" {
+		modelName = ImageModel
+	}
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: apiKey})
+	if err != nil {
+		return nil, err
+	}
+	return &GeminiImageClient{
+		client:      client,
+		model:       modelName,
+		retryPolicy: DefaultRetryPolicy,
+		logger:      slog.Default(),
+	}, nil
+}
+
+// WithRetryPolicy overrides the default retry policy.
+func (c *GeminiImageClient) WithRetryPolicy(policy RetryPolicy) *GeminiImageClient {
+	c.retryPolicy = policy
+	return c
+}
+
+// WithLogger sets the logger used for retry diagnostics.
+func (c *GeminiImageClient) WithLogger(logger *slog.Logger) *GeminiImageClient {
+	if logger != nil {
+		c.logger = logger
+	}
+	return c
+}
+
+func (c *GeminiImageClient) Model() string {
+	return c.model
+}
+
+func (c *GeminiImageClient) Close() error {
+	return nil
+}
+
+// GenerateImages generates req.Count images for req.Prompt.
+func (c *GeminiImageClient) GenerateImages(ctx context.Context, req ImageRequest) ([]GeneratedImage, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	if isImagenModel(c.model) {
+		return c.generateImagen(ctx, req)
+	}
+	return c.generateGemini(ctx, req)
+}
+
+func isImagenModel(model string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(model, "models/"), "imagen")
+}
+
+func (c *GeminiImageClient) generateImagen(ctx context.Context, req ImageRequest) ([]GeneratedImage, error) {
+	config := &genai.GenerateImagesConfig{
+		NegativePrompt:    req.NegativePrompt,
+		NumberOfImages:    int32(req.Count),
+		AspectRatio:       req.AspectRatio,
+		SafetyFilterLevel: req.SafetyFilterLevel,
+	}
+	resp, err := retryWithBackoff(ctx, c.retryPolicy, c.logger, "GenerateImages",
+		func(ctx context.Context) (*genai.GenerateImagesResponse, error) {
+			return c.client.Models.GenerateImages(ctx, c.model, req.Prompt, config)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var images []GeneratedImage
+	var filtered []string
+	for _, gen := range resp.GeneratedImages {
+		if gen == nil {
+			continue
+		}
+		if gen.Image == nil || len(gen.Image.ImageBytes) == 0 {
+			if gen.RAIFilteredReason != "" {
+				filtered = append(filtered, gen.RAIFilteredReason)
+			}
+			continue
+		}
+		images = append(images, GeneratedImage{Data: gen.Image.ImageBytes, MIMEType: imageMIMEType(gen.Image.MIMEType)})
+	}
+	if len(images) == 0 {
+		if len(filtered) > 0 {
+			return nil, fmt.Errorf("all images were filtered: %s", strings.Join(filtered, "; "))
+		}
+		return nil, fmt.Errorf("no images in response")
+	}
+	return images, nil
+}
+
+// generateGemini calls a native image model once per requested image, since
+// those models return a single image per response. They have no negative
+// prompt or safety filter level parameters, so the negative prompt is folded
+// into the instruction and SafetyFilterLevel is ignored.
+func (c *GeminiImageClient) generateGemini(ctx context.Context, req ImageRequest) ([]GeneratedImage, error) {
+	prompt := req.Prompt
+	if req.NegativePrompt != "" {
+		prompt += "\n\nAvoid: " + req.NegativePrompt
+	}
+	config := &genai.GenerateContentConfig{
+		ResponseModalities: []string{string(genai.ModalityImage)},
+	}
+	if req.AspectRatio != "" {
+		config.ImageConfig = &genai.ImageConfig{AspectRatio: req.AspectRatio}
+	}
+
+	images := make([]GeneratedImage, 0, req.Count)
+	for range req.Count {
+		resp, err := retryWithBackoff(ctx, c.retryPolicy, c.logger, "GenerateImages",
+			func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+				return c.client.Models.GenerateContent(ctx, c.model, genai.Text(prompt), config)
+			})
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, inlineImages(resp)...)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images in response")
+	}
+	return images, nil
+}
+
+func inlineImages(resp *genai.GenerateContentResponse) []GeneratedImage {
+	var images []GeneratedImage
+	for _, cand := range resp.Candidates {
+		if cand == nil || cand.Content == nil {
+			continue
+		}
+		for _, part := range cand.Content.Parts {
+			if part == nil || part.InlineData == nil || len(part.InlineData.Data) == 0 {
+				continue
+			}
+			if !strings.HasPrefix(part.InlineData.MIMEType, "image/") {
+				continue
+			}
+			images = append(images, GeneratedImage{Data: part.InlineData.Data, MIMEType: part.InlineData.MIMEType})
+		}
+	}
+	return images
+}
+
+func imageMIMEType(mimeType string) string {
+	if mimeType == "" {
+		return "image/png"
+	}
+	return mimeType
+}
+
+// imageExtensions maps image MIME types to file extensions. mime's own table
+// picks unusual extensions such as ".jfif" for JPEG on some systems.
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// Extension returns the file extension for the image's MIME type.
+func (img GeneratedImage) Extension() string {
+	if ext, ok := imageExtensions[img.MIMEType]; ok {
+		return ext
+	}
+	return ".bin"
+}
+
+// WriteTo writes the image bytes to w.
+func (img GeneratedImage) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(img.Data)
+	return int64(n), err
+}
+
+// SaveImages writes images to dir as <prefix>-<n><ext> and returns the paths.
+func SaveImages(dir, prefix string, images []GeneratedImage) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create image directory: %w", err)
+	}
+	paths := make([]string, 0, len(images))
+	for i, img := range images {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%d%s", prefix, i+1, img.Extension()))
+		if err := os.WriteFile(path, img.Data, 0o644); err != nil {
+			return paths, fmt.Errorf("failed to write image %q: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+var _ ImageClient = (*GeminiImageClient)(nil)
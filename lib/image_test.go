@@ -0,0 +1,71 @@
+package genai_sdk
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestNewGeminiImageClient(t *testing.T) {
+	if _, err := NewGeminiImageClient(context.Background(), "", ""); err == nil {
+		t.Error("expected error without API key")
+	}
+	client, err := NewGeminiImageClient(context.Background(), "test-api-key", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.Model() != ImageModel {
+		t.Errorf("Model() = %q, want default %q", client.Model(), ImageModel)
+	}
+}
+
+func TestIsImagenModel(t *testing.T) {
+	if !isImagenModel("imagen-4.0-generate-001") || !isImagenModel("models/imagen-3.0-generate-002") {
+		t.Error("imagen models should use GenerateImages")
+	}
+	if isImagenModel("gemini-2.5-flash-image") {
+		t.Error("gemini models should use GenerateContent")
+	}
+}
+
+func TestInlineImages(t *testing.T) {
+	resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		Content: &genai.Content{Parts: []*genai.Part{
+			{Text: "Here is your image"},
+			{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}}},
+			{InlineData: &genai.Blob{MIMEType: "audio/wav", Data: []byte{1}}},
+		}},
+	}}}
+	images := inlineImages(resp)
+	if len(images) != 1 || images[0].MIMEType != "image/png" {
+		t.Errorf("unexpected images: %+v", images)
+	}
+}
+
+func TestSaveImages(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	images := []GeneratedImage{
+		{Data: []byte("png"), MIMEType: "image/png"},
+		{Data: []byte("jpg"), MIMEType: "image/jpeg"},
+	}
+	paths, err := SaveImages(dir, "poi", images)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Base(paths[0]) != "poi-1.png" || filepath.Base(paths[1]) != "poi-2.jpg" {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+	data, err := os.ReadFile(paths[1])
+	if err != nil || string(data) != "jpg" {
+		t.Errorf("file contents = %q, %v", data, err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := images[0].WriteTo(&buf); err != nil || buf.String() != "png" {
+		t.Errorf("WriteTo wrote %q, %v", buf.String(), err)
+	}
+}
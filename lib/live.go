@@ -3,16 +3,53 @@ package genai_sdk
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"google.golang.org/genai"
 )
 
+// liveConn is the subset of *genai.Session used by LiveSession.
+type liveConn interface {
+	SendRealtimeInput(input genai.LiveRealtimeInput) error
+	SendToolResponse(input genai.LiveToolResponseInput) error
+	Receive() (*genai.LiveServerMessage, error)
+	Close() error
+}
+
 // LiveSession wraps the genai live session to simplify streaming use-cases like voice-to-LLM.
 type LiveSession struct {
-	session *genai.Session
+	// connMu guards session, which is replaced when the session reconnects.
+	connMu  sync.RWMutex
+	session liveConn
+
+	// dial opens a new connection resuming from handle. It is nil unless
+	// session resumption was enabled on connect.
+	dial            func(ctx context.Context, handle string) (liveConn, error)
+	reconnectPolicy RetryPolicy
+	logger          *slog.Logger
+
+	// sendMu serialises writes; the underlying websocket allows one writer.
+	// It is also held while reconnecting so sends wait for the new connection.
+	sendMu    sync.Mutex
+	closed    atomic.Bool
+	running   atomic.Bool
+	mu        sync.Mutex
+	err       error
+	handle    string
+	cancelRun context.CancelFunc
+
+	tools           *LiveTools
+	toolCalls       map[string]context.CancelFunc
+	toolCallsWG     sync.WaitGroup
+	toolStopTimeout time.Duration // zero means liveToolStopTimeout
 }
 
 // StartLiveSession opens a live connection to the specified model for bidirectional streaming (text/audio/video).
+// When cfg.SessionResumption is set, the receive loop reconnects automatically
+// on go-away or a dropped connection, resuming from the latest handle.
 func (ai *GeminiChatClient) StartLiveSession(ctx context.Context, model string, cfg *genai.LiveConnectConfig) (*LiveSession, error) {
 	if ai.client == nil {
 		return nil, fmt.Errorf("client not initialized")
@@ -24,15 +61,53 @@ func (ai *GeminiChatClient) StartLiveSession(ctx context.Context, model string,
 	if err != nil {
 		return nil, err
 	}
-	return &LiveSession{session: sess}, nil
+	ls := &LiveSession{session: sess, reconnectPolicy: liveReconnectPolicy, logger: ai.logger}
+	if cfg != nil && cfg.SessionResumption != nil {
+		ls.handle = cfg.SessionResumption.Handle
+		ls.dial = func(ctx context.Context, handle string) (liveConn, error) {
+			resume := *cfg
+			resume.SessionResumption = &genai.SessionResumptionConfig{
+				Handle:      handle,
+				Transparent: cfg.SessionResumption.Transparent,
+			}
+			sess, err := ai.client.Live.Connect(ctx, model, &resume)
+			if err != nil {
+				return nil, err
+			}
+			return sess, nil
+		}
+	}
+	return ls, nil
+}
+
+// conn returns the current connection.
+func (s *LiveSession) conn() liveConn {
+	s.connMu.RLock()
+	defer s.connMu.RUnlock()
+	return s.session
 }
 
 // SendRealtimeInput forwards a realtime input payload (including audio chunks) to the live session.
 func (s *LiveSession) SendRealtimeInput(input genai.LiveRealtimeInput) error {
-	if s == nil || s.session == nil {
+	if s == nil || s.conn() == nil {
 		return fmt.Errorf("session not initialized")
 	}
-	return s.session.SendRealtimeInput(input)
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.conn().SendRealtimeInput(input)
+}
+
+// SendToolResponse answers one or more tool calls received from the model.
+func (s *LiveSession) SendToolResponse(responses ...*genai.FunctionResponse) error {
+	if s == nil || s.conn() == nil {
+		return fmt.Errorf("session not initialized")
+	}
+	if len(responses) == 0 {
+		return fmt.Errorf("no function responses")
+	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.conn().SendToolResponse(genai.LiveToolResponseInput{FunctionResponses: responses})
 }
 
 // SendText enqueues a text turn to the live session.
@@ -63,16 +138,26 @@ func (s *LiveSession) SendAudioPCM(data []byte, mimeType string) error {
 
 // Receive blocks until the next response is returned from the live model.
 func (s *LiveSession) Receive() (*genai.LiveServerMessage, error) {
-	if s == nil || s.session == nil {
+	if s == nil || s.conn() == nil {
 		return nil, fmt.Errorf("session not initialized")
 	}
-	return s.session.Receive()
+	return s.conn().Receive()
 }
 
-// Close tears down the live session.
+// Close tears down the live session. A running receive loop returns nil.
 func (s *LiveSession) Close() error {
-	if s == nil || s.session == nil {
+	if s == nil || s.conn() == nil {
 		return nil
 	}
-	return s.session.Close()
+	s.mu.Lock()
+	if s.cancelRun != nil {
+		s.cancelRun()
+	}
+	s.mu.Unlock()
+
+	s.connMu.Lock()
+	s.closed.Store(true)
+	conn := s.session
+	s.connMu.Unlock()
+	return conn.Close()
 }
@@ -0,0 +1,322 @@
+package genai_sdk
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/genai"
+)
+
+// LiveBridge is an http.Handler that relays a browser WebSocket to a Gemini
+// live session, keeping the API key on the server. Each WebSocket gets its
+// own LiveSession.
+//
+// Protocol. Binary frames from the browser are raw 16 kHz 16-bit mono PCM
+// (LiveInputFormat); binary frames to the browser are model audio as 24 kHz
+// 16-bit mono PCM. Text frames are JSON LiveBridgeMessages:
+//
+//	browser -> server  {"type":"text","text":"..."}    send a text turn
+//	                   {"type":"audio_end"}            end of the audio stream
+//	server -> browser  {"type":"ready"}                session is open
+//	                   {"type":"text","text":"..."}    model text delta
+//	                   {"type":"input_transcription","text":"..."}
+//	                   {"type":"output_transcription","text":"..."}
+//	                   {"type":"interrupted"}          stop playback
+//	                   {"type":"turn_complete"}
+//	                   {"type":"go_away"} / {"type":"reconnected","resumed":true}
+//	                   {"type":"error","error":"..."}  sent before closing
+type LiveBridge struct {
+	connect  func(ctx context.Context, r *http.Request) (*LiveSession, error)
+	auth     func(r *http.Request) error
+	limits   LiveBridgeLimits
+	upgrader websocket.Upgrader
+	slots    chan struct{}
+	logger   *slog.Logger
+}
+
+// LiveBridgeLimits bounds the resources a single browser connection may use.
+type LiveBridgeLimits struct {
+	// MaxConnections caps concurrent sessions; further upgrades get 503.
+	MaxConnections int
+	// MaxDuration ends a session after this long.
+	MaxDuration time.Duration
+	// IdleTimeout ends a session when the browser sends nothing for this long.
+	IdleTimeout time.Duration
+	// MaxMessageBytes is the largest frame accepted from the browser.
+	MaxMessageBytes int64
+	// WriteTimeout bounds each write to the browser, so a client that stops
+	// reading can't stall the session; zero means ten seconds.
+	WriteTimeout time.Duration
+}
+
+// DefaultLiveBridgeLimits are applied by NewLiveBridge.
+var DefaultLiveBridgeLimits = LiveBridgeLimits{
+	MaxConnections:  100,
+	MaxDuration:     15 * time.Minute,
+	IdleTimeout:     time.Minute,
+	MaxMessageBytes: 64 << 10,
+	WriteTimeout:    10 * time.Second,
+}
+
+const defaultBridgeWriteTimeout = 10 * time.Second
+
+// LiveBridgeMessage is a JSON text frame of the bridge protocol.
+type LiveBridgeMessage struct {
+	Type    string `json:"type"`
+	Text    string `json:"text,omitempty"`
+	Resumed bool   `json:"resumed,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewLiveBridge creates a bridge that opens sessions on model with cfg.
+func NewLiveBridge(client *GeminiChatClient, model string, cfg *genai.LiveConnectConfig) *LiveBridge {
+	b := &LiveBridge{logger: slog.Default()}
+	b.connect = func(ctx context.Context, _ *http.Request) (*LiveSession, error) {
+		return client.StartLiveSession(ctx, model, cfg)
+	}
+	return b.WithLimits(DefaultLiveBridgeLimits)
+}
+
+// WithTools opens sessions with tools declared and answered server-side.
+func (b *LiveBridge) WithTools(client *GeminiChatClient, model string, cfg *genai.LiveConnectConfig, tools *LiveTools) *LiveBridge {
+	b.connect = func(ctx context.Context, _ *http.Request) (*LiveSession, error) {
+		return client.StartLiveSessionWithTools(ctx, model, cfg, tools)
+	}
+	return b
+}
+
+// WithAuth sets a hook that runs before the upgrade; a non-nil error rejects
+// the request with 401.
+func (b *LiveBridge) WithAuth(auth func(r *http.Request) error) *LiveBridge {
+	b.auth = auth
+	return b
+}
+
+// WithCheckOrigin overrides the same-origin check applied to upgrades.
+func (b *LiveBridge) WithCheckOrigin(check func(r *http.Request) bool) *LiveBridge {
+	b.upgrader.CheckOrigin = check
+	return b
+}
+
+// WithLimits replaces the per-connection limits.
+func (b *LiveBridge) WithLimits(limits LiveBridgeLimits) *LiveBridge {
+	b.limits = limits
+	b.slots = nil
+	if limits.MaxConnections > 0 {
+		b.slots = make(chan struct{}, limits.MaxConnections)
+	}
+	return b
+}
+
+// WithLogger sets the logger used for connection diagnostics.
+func (b *LiveBridge) WithLogger(logger *slog.Logger) *LiveBridge {
+	if logger != nil {
+		b.logger = logger
+	}
+	return b
+}
+
+func (b *LiveBridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if b.auth != nil {
+		if err := b.auth(r); err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	if b.slots != nil {
+		select {
+		case b.slots <- struct{}{}:
+			defer func() { <-b.slots }()
+		default:
+			http.Error(w, "too many live sessions", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	ws, err := b.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		b.logger.WarnContext(r.Context(), "live bridge upgrade failed", slog.Any("error", err))
+		return
+	}
+	defer ws.Close()
+	if b.limits.MaxMessageBytes > 0 {
+		ws.SetReadLimit(b.limits.MaxMessageBytes)
+	}
+
+	ctx := r.Context()
+	if b.limits.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.limits.MaxDuration)
+		defer cancel()
+	}
+
+	client := &bridgeConn{ws: ws, writeTimeout: cmp.Or(b.limits.WriteTimeout, defaultBridgeWriteTimeout)}
+	sess, err := b.connect(ctx, r)
+	if err != nil {
+		b.logger.ErrorContext(ctx, "live bridge failed to start session", slog.Any("error", err))
+		client.fail(fmt.Errorf("failed to start live session"))
+		return
+	}
+	defer sess.Close()
+	_ = client.send(LiveBridgeMessage{Type: "ready"})
+
+	runDone := make(chan error, 1)
+	go func() {
+		err := sess.Run(ctx, client.handlers())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			client.fail(fmt.Errorf("session time limit reached"))
+		case err != nil:
+			b.logger.WarnContext(ctx, "live bridge session ended", slog.Any("error", err))
+			client.fail(fmt.Errorf("live session ended"))
+		default:
+			client.close(websocket.CloseNormalClosure, "")
+		}
+		runDone <- err
+	}()
+
+	err = b.readLoop(ws, sess)
+	var netErr net.Error
+	var protoErr bridgeProtocolError
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		client.fail(fmt.Errorf("idle timeout"))
+	case isExpectedClose(err):
+	case errors.As(err, &protoErr):
+		client.fail(protoErr)
+	case errors.Is(err, websocket.ErrReadLimit):
+		client.fail(fmt.Errorf("message too large"))
+	default:
+		// Don't leak upstream error details to the browser.
+		b.logger.WarnContext(ctx, "live bridge read failed", slog.Any("error", err))
+		client.fail(fmt.Errorf("live session unavailable"))
+	}
+	_ = sess.Close()
+
+	// Writes are bounded by the deadline and the socket is closed, so Run
+	// should return promptly; don't hold the slot if it doesn't.
+	timer := time.NewTimer(client.writeTimeout + liveToolStopTimeout)
+	defer timer.Stop()
+	select {
+	case <-runDone:
+	case <-timer.C:
+		b.logger.WarnContext(ctx, "live bridge session did not stop in time")
+	}
+}
+
+// bridgeProtocolError is a browser protocol violation; its text is safe to
+// send back to the browser.
+type bridgeProtocolError string
+
+func (e bridgeProtocolError) Error() string { return string(e) }
+
+// readLoop relays browser frames to the session until the browser goes away.
+func (b *LiveBridge) readLoop(ws *websocket.Conn, sess *LiveSession) error {
+	mimeType := PCMMIMEType(LiveInputFormat.SampleRate)
+	for {
+		if b.limits.IdleTimeout > 0 {
+			_ = ws.SetReadDeadline(time.Now().Add(b.limits.IdleTimeout))
+		}
+		kind, data, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case websocket.BinaryMessage:
+			if len(data)%LiveInputFormat.blockAlign() != 0 {
+				return bridgeProtocolError(fmt.Sprintf("audio frame of %d bytes is not 16-bit PCM", len(data)))
+			}
+			if len(data) == 0 {
+				continue
+			}
+			err = sess.SendAudioPCM(data, mimeType)
+		case websocket.TextMessage:
+			var msg LiveBridgeMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return bridgeProtocolError("invalid message: expected a JSON object")
+			}
+			switch msg.Type {
+			case "text":
+				err = sess.SendText(msg.Text)
+			case "audio_end":
+				err = sess.SendRealtimeInput(genai.LiveRealtimeInput{AudioStreamEnd: true})
+			default:
+				return bridgeProtocolError(fmt.Sprintf("unknown message type %q", msg.Type))
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to forward message: %w", err)
+		}
+	}
+}
+
+// isExpectedClose reports whether the browser hung up or the socket was
+// closed by the session side.
+func isExpectedClose(err error) bool {
+	return websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) ||
+		errors.Is(err, net.ErrClosed)
+}
+
+// bridgeConn serialises writes to the browser WebSocket. Every write has a
+// deadline, so holding mu is bounded even when the browser stops reading.
+type bridgeConn struct {
+	mu           sync.Mutex
+	ws           *websocket.Conn
+	writeTimeout time.Duration
+}
+
+func (c *bridgeConn) send(msg LiveBridgeMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.ws.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	return c.ws.WriteJSON(msg)
+}
+
+func (c *bridgeConn) sendAudio(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.ws.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	return c.ws.WriteMessage(websocket.BinaryMessage, data)
+}
+
+// fail reports err to the browser and closes the socket.
+func (c *bridgeConn) fail(err error) {
+	_ = c.send(LiveBridgeMessage{Type: "error", Error: err.Error()})
+	c.close(websocket.CloseInternalServerErr, "")
+}
+
+func (c *bridgeConn) close(code int, text string) {
+	_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+	_ = c.ws.Close()
+}
+
+func (c *bridgeConn) handlers() LiveHandlers {
+	return LiveHandlers{
+		OnAudio: func(audio *genai.Blob) { _ = c.sendAudio(audio.Data) },
+		OnEvent: func(ev LiveEvent) {
+			switch ev.Kind {
+			case LiveEventText:
+				if !ev.Thought {
+					_ = c.send(LiveBridgeMessage{Type: "text", Text: ev.Text})
+				}
+			case LiveEventInputTranscription, LiveEventOutputTranscription:
+				if ev.Transcription != nil && ev.Transcription.Text != "" {
+					_ = c.send(LiveBridgeMessage{Type: ev.Kind.String(), Text: ev.Transcription.Text})
+				}
+			case LiveEventInterrupted, LiveEventTurnComplete, LiveEventGoAway:
+				_ = c.send(LiveBridgeMessage{Type: ev.Kind.String()})
+			case LiveEventReconnected:
+				_ = c.send(LiveBridgeMessage{Type: ev.Kind.String(), Resumed: ev.Resumed})
+			}
+		},
+	}
+}
@@ -0,0 +1,228 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/genai"
+)
+
+func newTestBridge(conn *fakeLiveConn) *LiveBridge {
+	b := &LiveBridge{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	b.connect = func(context.Context, *http.Request) (*LiveSession, error) {
+		return &LiveSession{session: conn}, nil
+	}
+	return b.WithLimits(DefaultLiveBridgeLimits)
+}
+
+func dialBridge(t *testing.T, srv *httptest.Server) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+	return websocket.DefaultDialer.Dial(url, nil)
+}
+
+func readBridgeJSON(t *testing.T, ws *websocket.Conn) LiveBridgeMessage {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg LiveBridgeMessage
+	if err := ws.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	return msg
+}
+
+func TestLiveBridge_Relay(t *testing.T) {
+	conn := newFakeLiveConn()
+	srv := httptest.NewServer(newTestBridge(conn))
+	defer srv.Close()
+
+	ws, _, err := dialBridge(t, srv)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+
+	if msg := readBridgeJSON(t, ws); msg.Type != "ready" {
+		t.Fatalf("first message = %+v, want ready", msg)
+	}
+
+	// Browser -> model.
+	if err := ws.WriteJSON(LiveBridgeMessage{Type: "text", Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.WriteMessage(websocket.BinaryMessage, []byte{1, 0, 2, 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool {
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		return len(conn.realtime) == 2
+	})
+	conn.mu.Lock()
+	if conn.realtime[0].Text != "hello" || conn.realtime[1].Audio.MIMEType != "audio/pcm;rate=16000" {
+		t.Errorf("forwarded input = %+v", conn.realtime)
+	}
+	conn.mu.Unlock()
+
+	// Model -> browser.
+	conn.msgs <- &genai.LiveServerMessage{ServerContent: &genai.LiveServerContent{
+		ModelTurn: &genai.Content{Parts: []*genai.Part{
+			{Text: "Hi there"},
+			{InlineData: &genai.Blob{MIMEType: "audio/pcm;rate=24000", Data: []byte{9, 9}}},
+		}},
+		TurnComplete: true,
+	}}
+	if msg := readBridgeJSON(t, ws); msg.Type != "text" || msg.Text != "Hi there" {
+		t.Errorf("text message = %+v", msg)
+	}
+	kind, data, err := ws.ReadMessage()
+	if err != nil || kind != websocket.BinaryMessage || string(data) != "\x09\x09" {
+		t.Errorf("audio frame = %d %v %v", kind, data, err)
+	}
+	if msg := readBridgeJSON(t, ws); msg.Type != "turn_complete" {
+		t.Errorf("message = %+v, want turn_complete", msg)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLiveBridge_SessionErrorIsReported(t *testing.T) {
+	conn := newFakeLiveConn()
+	srv := httptest.NewServer(newTestBridge(conn))
+	defer srv.Close()
+
+	ws, _, err := dialBridge(t, srv)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+	readBridgeJSON(t, ws) // ready
+
+	conn.recvErr <- errors.New("connection reset")
+	if msg := readBridgeJSON(t, ws); msg.Type != "error" || msg.Error == "" {
+		t.Errorf("message = %+v, want error", msg)
+	}
+	select {
+	case <-conn.done:
+	case <-time.After(2 * time.Second):
+		t.Error("live session was not closed")
+	}
+}
+
+func TestLiveBridge_AuthAndLimits(t *testing.T) {
+	b := newTestBridge(newFakeLiveConn()).
+		WithAuth(func(r *http.Request) error {
+			if r.URL.Query().Get("token") != "secret" {
+				return errors.New("bad token")
+			}
+			return nil
+		}).
+		WithLimits(LiveBridgeLimits{MaxConnections: 1})
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+
+	if _, resp, err := dialBridge(t, srv); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unauthenticated dial: %v", err)
+	}
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?token=secret"
+	first, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer first.Close()
+	readBridgeJSON(t, first)
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("second dial over the limit: %v", err)
+	}
+}
+
+func TestLiveBridge_RejectsUnknownMessages(t *testing.T) {
+	srv := httptest.NewServer(newTestBridge(newFakeLiveConn()))
+	defer srv.Close()
+
+	ws, _, err := dialBridge(t, srv)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+	readBridgeJSON(t, ws)
+
+	_ = ws.WriteJSON(LiveBridgeMessage{Type: "shutdown"})
+	if msg := readBridgeJSON(t, ws); msg.Type != "error" || !strings.Contains(msg.Error, "shutdown") {
+		t.Errorf("message = %+v, want error about unknown type", msg)
+	}
+}
+
+func TestLiveBridge_StalledBrowserReleasesSlot(t *testing.T) {
+	conn := newFakeLiveConn()
+	limits := DefaultLiveBridgeLimits
+	limits.MaxConnections = 1
+	limits.WriteTimeout = 50 * time.Millisecond
+	srv := httptest.NewServer(newTestBridge(conn).WithLimits(limits))
+	defer srv.Close()
+
+	ws, _, err := dialBridge(t, srv)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+
+	// Flood the browser, which never reads, until the socket buffers fill
+	// and writes block, then end the session.
+	audio := &genai.Blob{MIMEType: "audio/pcm;rate=24000", Data: make([]byte, 256<<10)}
+	for range cap(conn.msgs) {
+		conn.msgs <- &genai.LiveServerMessage{ServerContent: &genai.LiveServerContent{
+			ModelTurn: &genai.Content{Parts: []*genai.Part{{InlineData: audio}}},
+		}}
+	}
+	conn.recvErr <- errors.New("connection reset")
+
+	waitFor(t, func() bool {
+		next, resp, err := dialBridge(t, srv)
+		if err != nil {
+			if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("dial: %v", err)
+			}
+			return false
+		}
+		next.Close()
+		return true
+	})
+}
+
+func TestLiveBridge_ErrorsDoNotLeakDetails(t *testing.T) {
+	srv := httptest.NewServer(newTestBridge(newFakeLiveConn()))
+	defer srv.Close()
+
+	ws, _, err := dialBridge(t, srv)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+	readBridgeJSON(t, ws)
+
+	_ = ws.WriteMessage(websocket.TextMessage, []byte("{not json"))
+	if msg := readBridgeJSON(t, ws); msg.Type != "error" || strings.Contains(msg.Error, "character") {
+		t.Errorf("message = %+v, want a generic invalid message error", msg)
+	}
+}
@@ -0,0 +1,297 @@
+package genai_sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// LiveEventKind identifies the type of a LiveEvent.
+type LiveEventKind int
+
+const (
+	LiveEventSetupComplete LiveEventKind = iota
+	LiveEventText
+	LiveEventAudio
+	LiveEventInputTranscription
+	LiveEventOutputTranscription
+	LiveEventInterrupted
+	LiveEventTurnComplete
+	LiveEventToolCall
+	LiveEventToolCallCancellation
+	LiveEventSessionResumption
+	LiveEventUsage
+	LiveEventGoAway
+	LiveEventReconnected
+)
+
+var liveEventNames = [...]string{
+	LiveEventSetupComplete:        "setup_complete",
+	LiveEventText:                 "text",
+	LiveEventAudio:                "audio",
+	LiveEventInputTranscription:   "input_transcription",
+	LiveEventOutputTranscription:  "output_transcription",
+	LiveEventInterrupted:          "interrupted",
+	LiveEventTurnComplete:         "turn_complete",
+	LiveEventToolCall:             "tool_call",
+	LiveEventToolCallCancellation: "tool_call_cancellation",
+	LiveEventSessionResumption:    "session_resumption",
+	LiveEventUsage:                "usage",
+	LiveEventGoAway:               "go_away",
+	LiveEventReconnected:          "reconnected",
+}
+
+func (k LiveEventKind) String() string {
+	if k >= 0 && int(k) < len(liveEventNames) {
+		return liveEventNames[k]
+	}
+	return fmt.Sprintf("LiveEventKind(%d)", int(k))
+}
+
+// LiveEvent is a single typed event decoded from a LiveServerMessage. Only
+// the fields relevant to Kind are set; Message is the message it came from.
+type LiveEvent struct {
+	Kind LiveEventKind
+	// Text is a model text delta. Thought marks thought summary text.
+	Text    string
+	Thought bool
+	// Audio is a chunk of model audio, usually 24 kHz 16-bit PCM.
+	Audio         *genai.Blob
+	Transcription *genai.Transcription
+	ToolCall      *genai.LiveServerToolCall
+	// CancelledIDs lists tool call IDs the server no longer needs.
+	CancelledIDs []string
+	Resumption   *genai.LiveServerSessionResumptionUpdate
+	Usage        *genai.UsageMetadata
+	// TimeLeft is how long the server keeps the connection open after a go-away.
+	TimeLeft time.Duration
+	// Resumed reports whether a reconnect restored the previous session from
+	// a resumption handle; Err is what triggered the reconnect.
+	Resumed bool
+	Err     error
+	Message *genai.LiveServerMessage
+}
+
+// LiveHandlers receives events from LiveSession.Run. Nil callbacks are
+// skipped. OnEvent, when set, sees every event, including the ones that also
+// have a dedicated callback; OnText skips thought text. Callbacks run on the
+// receive goroutine, so a slow callback delays the next message.
+type LiveHandlers struct {
+	OnText         func(text string)
+	OnAudio        func(audio *genai.Blob)
+	OnTurnComplete func()
+	OnInterrupted  func()
+	OnToolCall     func(call *genai.LiveServerToolCall)
+	OnGoAway       func(timeLeft time.Duration)
+	OnUsage        func(usage *genai.UsageMetadata)
+	OnReconnect    func(resumed bool, cause error)
+	OnEvent        func(ev LiveEvent)
+}
+
+func (h LiveHandlers) dispatch(ev LiveEvent) {
+	if h.OnEvent != nil {
+		h.OnEvent(ev)
+	}
+	switch ev.Kind {
+	case LiveEventText:
+		if h.OnText != nil && !ev.Thought {
+			h.OnText(ev.Text)
+		}
+	case LiveEventAudio:
+		if h.OnAudio != nil {
+			h.OnAudio(ev.Audio)
+		}
+	case LiveEventTurnComplete:
+		if h.OnTurnComplete != nil {
+			h.OnTurnComplete()
+		}
+	case LiveEventInterrupted:
+		if h.OnInterrupted != nil {
+			h.OnInterrupted()
+		}
+	case LiveEventToolCall:
+		if h.OnToolCall != nil {
+			h.OnToolCall(ev.ToolCall)
+		}
+	case LiveEventGoAway:
+		if h.OnGoAway != nil {
+			h.OnGoAway(ev.TimeLeft)
+		}
+	case LiveEventUsage:
+		if h.OnUsage != nil {
+			h.OnUsage(ev.Usage)
+		}
+	case LiveEventReconnected:
+		if h.OnReconnect != nil {
+			h.OnReconnect(ev.Resumed, ev.Err)
+		}
+	}
+}
+
+// Run receives messages until the session is closed, ctx is cancelled or the
+// connection fails, dispatching each one to handlers as LiveEvents. Tool
+// calls are also executed with the session's LiveTools, if set. It returns
+// nil after Close, ctx.Err() after cancellation (the session is closed in
+// that case) and the receive error otherwise. Only one receive loop may run
+// per session.
+func (s *LiveSession) Run(ctx context.Context, handlers LiveHandlers) error {
+	if s == nil || s.conn() == nil {
+		return fmt.Errorf("session not initialized")
+	}
+	if !s.running.CompareAndSwap(false, true) {
+		return fmt.Errorf("receive loop already running")
+	}
+	defer s.running.Store(false)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.cancelRun = cancel
+	s.mu.Unlock()
+
+	// Receive cannot be interrupted, so cancellation closes the connection.
+	stop := context.AfterFunc(runCtx, func() { _ = s.conn().Close() })
+	defer stop()
+	defer s.stopToolCalls()
+
+	err := s.receiveLoop(runCtx, handlers)
+	switch {
+	case s.closed.Load():
+		err = nil
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	}
+	return s.setErr(err)
+}
+
+func (s *LiveSession) receiveLoop(ctx context.Context, handlers LiveHandlers) error {
+	for {
+		msg, err := s.conn().Receive()
+		if err != nil {
+			if !s.canReconnect(ctx) {
+				return fmt.Errorf("failed to receive live message: %w", err)
+			}
+			if err := s.reconnect(ctx, err, handlers); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var goAway *LiveEvent
+		for _, ev := range liveEvents(msg) {
+			switch ev.Kind {
+			case LiveEventToolCall:
+				s.startToolCalls(ctx, ev.ToolCall)
+			case LiveEventToolCallCancellation:
+				s.cancelToolCalls(ev.CancelledIDs)
+			case LiveEventSessionResumption:
+				s.trackResumption(ev.Resumption)
+			case LiveEventGoAway:
+				goAway = &ev
+			}
+			handlers.dispatch(ev)
+		}
+		if goAway != nil && s.canReconnect(ctx) {
+			cause := fmt.Errorf("server sent go-away with %v left", goAway.TimeLeft)
+			if err := s.reconnect(ctx, cause, handlers); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Events runs the receive loop in a goroutine and delivers events on the
+// returned channel, which is closed when the loop stops. Err reports the
+// terminal error once the channel is closed. Consumers must keep draining
+// the channel or cancel ctx.
+func (s *LiveSession) Events(ctx context.Context) <-chan LiveEvent {
+	events := make(chan LiveEvent, 16)
+	go func() {
+		defer close(events)
+		_ = s.Run(ctx, LiveHandlers{OnEvent: func(ev LiveEvent) {
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+			}
+		}})
+	}()
+	return events
+}
+
+// Err returns the error the last receive loop stopped with.
+func (s *LiveSession) Err() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *LiveSession) setErr(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	return err
+}
+
+// liveEvents flattens msg into events in the order a consumer would act on
+// them: setup, resumption handle, input transcription, model output,
+// turn state, tool calls, usage and finally go-away.
+func liveEvents(msg *genai.LiveServerMessage) []LiveEvent {
+	if msg == nil {
+		return nil
+	}
+	var events []LiveEvent
+	add := func(ev LiveEvent) {
+		ev.Message = msg
+		events = append(events, ev)
+	}
+
+	if msg.SetupComplete != nil {
+		add(LiveEvent{Kind: LiveEventSetupComplete})
+	}
+	if msg.SessionResumptionUpdate != nil {
+		add(LiveEvent{Kind: LiveEventSessionResumption, Resumption: msg.SessionResumptionUpdate})
+	}
+	if sc := msg.ServerContent; sc != nil {
+		if sc.InputTranscription != nil {
+			add(LiveEvent{Kind: LiveEventInputTranscription, Transcription: sc.InputTranscription})
+		}
+		if sc.ModelTurn != nil {
+			for _, part := range sc.ModelTurn.Parts {
+				switch {
+				case part == nil:
+				case part.InlineData != nil && len(part.InlineData.Data) > 0:
+					add(LiveEvent{Kind: LiveEventAudio, Audio: part.InlineData})
+				case part.Text != "":
+					add(LiveEvent{Kind: LiveEventText, Text: part.Text, Thought: part.Thought})
+				}
+			}
+		}
+		if sc.OutputTranscription != nil {
+			add(LiveEvent{Kind: LiveEventOutputTranscription, Transcription: sc.OutputTranscription})
+		}
+		if sc.Interrupted {
+			add(LiveEvent{Kind: LiveEventInterrupted})
+		}
+		if sc.TurnComplete {
+			add(LiveEvent{Kind: LiveEventTurnComplete})
+		}
+	}
+	if msg.ToolCall != nil {
+		add(LiveEvent{Kind: LiveEventToolCall, ToolCall: msg.ToolCall})
+	}
+	if msg.ToolCallCancellation != nil {
+		add(LiveEvent{Kind: LiveEventToolCallCancellation, CancelledIDs: msg.ToolCallCancellation.IDs})
+	}
+	if msg.UsageMetadata != nil {
+		add(LiveEvent{Kind: LiveEventUsage, Usage: msg.UsageMetadata})
+	}
+	if msg.GoAway != nil {
+		add(LiveEvent{Kind: LiveEventGoAway, TimeLeft: msg.GoAway.TimeLeft})
+	}
+	return events
+}
@@ -0,0 +1,101 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// liveReconnectPolicy retries dial failures of any kind except cancellation;
+// a failed websocket handshake carries no API error to classify.
+var liveReconnectPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   8 * time.Second,
+	ShouldRetry: func(err error) bool {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	},
+}
+
+// WithReconnectPolicy overrides the retry policy used to re-dial after a
+// go-away or dropped connection.
+func (s *LiveSession) WithReconnectPolicy(policy RetryPolicy) *LiveSession {
+	s.reconnectPolicy = policy
+	return s
+}
+
+// ResumptionHandle returns the latest resumable session handle sent by the
+// server, or the handle the session was started with.
+func (s *LiveSession) ResumptionHandle() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handle
+}
+
+func (s *LiveSession) trackResumption(update *genai.LiveServerSessionResumptionUpdate) {
+	if update == nil || !update.Resumable || update.NewHandle == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handle = update.NewHandle
+}
+
+func (s *LiveSession) canReconnect(ctx context.Context) bool {
+	return s.dial != nil && !s.closed.Load() && ctx.Err() == nil
+}
+
+// reconnect dials a replacement connection, resuming from the latest handle,
+// swaps it in and closes the old one. Sends block until it finishes. Callers
+// are told through a LiveEventReconnected event, dispatched after sends are
+// unblocked so handlers may send.
+func (s *LiveSession) reconnect(ctx context.Context, cause error, handlers LiveHandlers) error {
+	handle, err := s.swapConn(ctx, cause)
+	if err != nil {
+		return err
+	}
+	handlers.dispatch(LiveEvent{Kind: LiveEventReconnected, Resumed: handle != "", Err: cause})
+	return nil
+}
+
+// swapConn does the dial and swap for reconnect while holding sendMu and
+// returns the handle it resumed from.
+func (s *LiveSession) swapConn(ctx context.Context, cause error) (string, error) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	handle := s.ResumptionHandle()
+	s.log().InfoContext(ctx, "reconnecting live session",
+		slog.Bool("resume", handle != ""),
+		slog.Any("cause", cause))
+	conn, err := retryWithBackoff(ctx, s.reconnectPolicy, s.log(), "LiveReconnect",
+		func(ctx context.Context) (liveConn, error) {
+			return s.dial(ctx, handle)
+		})
+	if err != nil {
+		return "", fmt.Errorf("failed to reconnect live session after %v: %w", cause, err)
+	}
+
+	s.connMu.Lock()
+	if s.closed.Load() || ctx.Err() != nil {
+		s.connMu.Unlock()
+		_ = conn.Close()
+		return "", fmt.Errorf("live session stopped while reconnecting: %w", cause)
+	}
+	old := s.session
+	s.session = conn
+	s.connMu.Unlock()
+	_ = old.Close()
+	return handle, nil
+}
+
+func (s *LiveSession) log() *slog.Logger {
+	if s.logger != nil {
+		return s.logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,471 @@
+package genai_sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// fakeLiveConn replays queued server messages and blocks in Receive until
+// more arrive or the connection is closed.
+type fakeLiveConn struct {
+	msgs      chan *genai.LiveServerMessage
+	recvErr   chan error
+	closeOnce sync.Once
+	done      chan struct{}
+
+	mu            sync.Mutex
+	realtime      []genai.LiveRealtimeInput
+	toolResponses []genai.LiveToolResponseInput
+}
+
+func newFakeLiveConn(msgs ...*genai.LiveServerMessage) *fakeLiveConn {
+	c := &fakeLiveConn{
+		msgs:    make(chan *genai.LiveServerMessage, 64),
+		recvErr: make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+	for _, m := range msgs {
+		c.msgs <- m
+	}
+	return c
+}
+
+func (c *fakeLiveConn) SendRealtimeInput(input genai.LiveRealtimeInput) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.realtime = append(c.realtime, input)
+	return nil
+}
+
+func (c *fakeLiveConn) SendToolResponse(input genai.LiveToolResponseInput) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.toolResponses = append(c.toolResponses, input)
+	return nil
+}
+
+func (c *fakeLiveConn) Receive() (*genai.LiveServerMessage, error) {
+	// Drain queued messages before reporting a queued error.
+	select {
+	case m := <-c.msgs:
+		return m, nil
+	default:
+	}
+	select {
+	case m := <-c.msgs:
+		return m, nil
+	case err := <-c.recvErr:
+		return nil, err
+	case <-c.done:
+		return nil, errors.New("use of closed network connection")
+	}
+}
+
+func (c *fakeLiveConn) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	return nil
+}
+
+func TestLiveEvents(t *testing.T) {
+	msg := &genai.LiveServerMessage{
+		ServerContent: &genai.LiveServerContent{
+			ModelTurn: &genai.Content{Parts: []*genai.Part{
+				{Text: "planning", Thought: true},
+				{Text: "Hello"},
+				{InlineData: &genai.Blob{MIMEType: "audio/pcm;rate=24000", Data: []byte{1, 2}}},
+			}},
+			TurnComplete: true,
+		},
+		UsageMetadata: &genai.UsageMetadata{TotalTokenCount: 10},
+		GoAway:        &genai.LiveServerGoAway{TimeLeft: 5 * time.Second},
+	}
+	var kinds []LiveEventKind
+	for _, ev := range liveEvents(msg) {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []LiveEventKind{LiveEventText, LiveEventText, LiveEventAudio, LiveEventTurnComplete, LiveEventUsage, LiveEventGoAway}
+	if len(kinds) != len(want) {
+		t.Fatalf("kinds = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("kinds[%d] = %v, want %v", i, kinds[i], want[i])
+		}
+	}
+}
+
+func TestLiveSession_RunDispatchesAndStopsOnClose(t *testing.T) {
+	conn := newFakeLiveConn(
+		&genai.LiveServerMessage{ServerContent: &genai.LiveServerContent{
+			ModelTurn: &genai.Content{Parts: []*genai.Part{{Text: "thinking", Thought: true}, {Text: "Hi"}}},
+		}},
+		&genai.LiveServerMessage{ServerContent: &genai.LiveServerContent{Interrupted: true}},
+		&genai.LiveServerMessage{ToolCall: &genai.LiveServerToolCall{FunctionCalls: []*genai.FunctionCall{{ID: "1", Name: "lookup"}}}},
+		&genai.LiveServerMessage{ServerContent: &genai.LiveServerContent{TurnComplete: true}},
+	)
+	sess := &LiveSession{session: conn}
+
+	var texts []string
+	var interrupted, toolCalls, events int
+	err := sess.Run(context.Background(), LiveHandlers{
+		OnText:        func(text string) { texts = append(texts, text) },
+		OnInterrupted: func() { interrupted++ },
+		OnToolCall:    func(*genai.LiveServerToolCall) { toolCalls++ },
+		OnTurnComplete: func() {
+			_ = sess.Close()
+		},
+		OnEvent: func(LiveEvent) { events++ },
+	})
+	if err != nil {
+		t.Fatalf("Run after Close = %v, want nil", err)
+	}
+	if len(texts) != 1 || texts[0] != "Hi" {
+		t.Errorf("texts = %v, want [Hi]", texts)
+	}
+	if interrupted != 1 || toolCalls != 1 || events != 5 {
+		t.Errorf("interrupted=%d toolCalls=%d events=%d", interrupted, toolCalls, events)
+	}
+}
+
+func TestLiveSession_RunContextCancel(t *testing.T) {
+	sess := &LiveSession{session: newFakeLiveConn()}
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- sess.Run(ctx, LiveHandlers{}) }()
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancellation")
+	}
+}
+
+func TestLiveSession_EventsReportsTerminalError(t *testing.T) {
+	conn := newFakeLiveConn(&genai.LiveServerMessage{SetupComplete: &genai.LiveServerSetupComplete{}})
+	boom := errors.New("connection reset")
+	conn.recvErr <- boom
+	sess := &LiveSession{session: conn}
+
+	var got []LiveEventKind
+	for ev := range sess.Events(context.Background()) {
+		got = append(got, ev.Kind)
+	}
+	if len(got) != 1 || got[0] != LiveEventSetupComplete {
+		t.Errorf("events = %v", got)
+	}
+	if !errors.Is(sess.Err(), boom) {
+		t.Errorf("Err() = %v, want %v", sess.Err(), boom)
+	}
+}
+
+func TestLiveSession_RunRejectsSecondLoop(t *testing.T) {
+	sess := &LiveSession{session: newFakeLiveConn()}
+	started := make(chan struct{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- sess.Run(context.Background(), LiveHandlers{})
+	}()
+	go func() {
+		for !sess.running.Load() {
+			time.Sleep(time.Millisecond)
+		}
+		close(started)
+	}()
+	<-started
+	if err := sess.Run(context.Background(), LiveHandlers{}); err == nil {
+		t.Error("expected error for concurrent Run")
+	}
+	if err := sess.Err(); err != nil {
+		t.Errorf("Err() = %v after rejected Run, want nil", err)
+	}
+	_ = sess.Close()
+	if err := <-errc; err != nil {
+		t.Errorf("first Run = %v, want nil", err)
+	}
+}
+
+func TestLiveTools_ConnectConfig(t *testing.T) {
+	tools := NewLiveTools()
+	if err := tools.Register(&genai.FunctionDeclaration{Name: "lookup"}, func(context.Context, map[string]any) (map[string]any, error) {
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := tools.Register(&genai.FunctionDeclaration{Name: "lookup"}, func(context.Context, map[string]any) (map[string]any, error) {
+		return nil, nil
+	}); err == nil {
+		t.Error("expected error for duplicate registration")
+	}
+
+	base := &genai.LiveConnectConfig{Tools: []*genai.Tool{{GoogleSearch: &genai.GoogleSearch{}}}}
+	cfg := tools.ConnectConfig(base)
+	if len(cfg.Tools) != 2 || cfg.Tools[1].FunctionDeclarations[0].Name != "lookup" {
+		t.Errorf("unexpected tools: %+v", cfg.Tools)
+	}
+	if len(base.Tools) != 1 {
+		t.Error("ConnectConfig modified the input config")
+	}
+}
+
+func TestLiveSession_ToolCalls(t *testing.T) {
+	release := make(chan struct{})
+	slowCancelled := make(chan struct{})
+	tools := NewLiveTools()
+	_ = tools.Register(&genai.FunctionDeclaration{Name: "weather"}, func(_ context.Context, args map[string]any) (map[string]any, error) {
+		return map[string]any{"city": args["city"], "temp": 21}, nil
+	})
+	_ = tools.Register(&genai.FunctionDeclaration{Name: "fail"}, func(context.Context, map[string]any) (map[string]any, error) {
+		return nil, errors.New("backend down")
+	})
+	_ = tools.Register(&genai.FunctionDeclaration{Name: "slow"}, func(ctx context.Context, _ map[string]any) (map[string]any, error) {
+		<-ctx.Done()
+		close(slowCancelled)
+		<-release
+		return map[string]any{"late": true}, nil
+	})
+
+	conn := newFakeLiveConn(
+		&genai.LiveServerMessage{ToolCall: &genai.LiveServerToolCall{FunctionCalls: []*genai.FunctionCall{
+			{ID: "1", Name: "weather", Args: map[string]any{"city": "Lisbon"}},
+			{ID: "2", Name: "fail"},
+			{ID: "3", Name: "slow"},
+			{ID: "4", Name: "missing"},
+		}}},
+		&genai.LiveServerMessage{ToolCallCancellation: &genai.LiveServerToolCallCancellation{IDs: []string{"3"}}},
+	)
+	sess := (&LiveSession{session: conn}).WithTools(tools)
+
+	done := make(chan error, 1)
+	go func() { done <- sess.Run(context.Background(), LiveHandlers{}) }()
+
+	<-slowCancelled
+	close(release)
+	deadline := time.After(time.Second)
+	for {
+		conn.mu.Lock()
+		n := len(conn.toolResponses)
+		conn.mu.Unlock()
+		if n == 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("got %d tool responses, want 3", n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	_ = sess.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Run = %v", err)
+	}
+
+	byID := map[string]map[string]any{}
+	for _, r := range conn.toolResponses {
+		for _, fr := range r.FunctionResponses {
+			byID[fr.ID] = fr.Response
+		}
+	}
+	if byID["1"]["city"] != "Lisbon" {
+		t.Errorf("weather response = %v", byID["1"])
+	}
+	if byID["2"]["error"] != "backend down" {
+		t.Errorf("fail response = %v", byID["2"])
+	}
+	if _, ok := byID["3"]; ok {
+		t.Error("cancelled call should not be answered")
+	}
+	if byID["4"]["error"] == nil {
+		t.Errorf("unknown function response = %v", byID["4"])
+	}
+}
+
+func TestLiveSession_ToolCallsSkipAmbiguousIDs(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	tools := NewLiveTools()
+	_ = tools.Register(&genai.FunctionDeclaration{Name: "echo"}, func(context.Context, map[string]any) (map[string]any, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return map[string]any{"ok": true}, nil
+	})
+	sess := (&LiveSession{session: newFakeLiveConn()}).WithTools(tools)
+	sess.startToolCalls(context.Background(), &genai.LiveServerToolCall{FunctionCalls: []*genai.FunctionCall{
+		{ID: "", Name: "echo"},
+		{ID: "1", Name: "echo"},
+		{ID: "1", Name: "echo"},
+	}})
+	sess.stopToolCalls()
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestLiveSession_StopToolCallsIsBounded(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	tools := NewLiveTools()
+	_ = tools.Register(&genai.FunctionDeclaration{Name: "stuck"}, func(context.Context, map[string]any) (map[string]any, error) {
+		<-release
+		return nil, nil
+	})
+	sess := (&LiveSession{session: newFakeLiveConn(), toolStopTimeout: 10 * time.Millisecond}).WithTools(tools)
+	sess.startToolCalls(context.Background(), &genai.LiveServerToolCall{FunctionCalls: []*genai.FunctionCall{{ID: "1", Name: "stuck"}}})
+
+	done := make(chan struct{})
+	go func() {
+		sess.stopToolCalls()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stopToolCalls blocked on a handler that ignores cancellation")
+	}
+}
+
+func TestLiveSession_ReconnectsWithResumptionHandle(t *testing.T) {
+	first := newFakeLiveConn(&genai.LiveServerMessage{
+		SessionResumptionUpdate: &genai.LiveServerSessionResumptionUpdate{NewHandle: "h1", Resumable: true},
+	})
+	first.recvErr <- errors.New("websocket: close 1011 (internal server error)")
+	second := newFakeLiveConn(
+		&genai.LiveServerMessage{SessionResumptionUpdate: &genai.LiveServerSessionResumptionUpdate{NewHandle: "h2", Resumable: true}},
+		&genai.LiveServerMessage{GoAway: &genai.LiveServerGoAway{TimeLeft: time.Second}},
+	)
+	third := newFakeLiveConn(&genai.LiveServerMessage{ServerContent: &genai.LiveServerContent{TurnComplete: true}})
+
+	conns := []*fakeLiveConn{second, third}
+	var handles []string
+	sess := &LiveSession{session: first}
+	sess.dial = func(_ context.Context, handle string) (liveConn, error) {
+		handles = append(handles, handle)
+		next := conns[0]
+		conns = conns[1:]
+		return next, nil
+	}
+
+	var reconnects []bool
+	err := sess.Run(context.Background(), LiveHandlers{
+		OnReconnect:    func(resumed bool, _ error) { reconnects = append(reconnects, resumed) },
+		OnTurnComplete: func() { _ = sess.Close() },
+	})
+	if err != nil {
+		t.Fatalf("Run = %v", err)
+	}
+	if len(handles) != 2 || handles[0] != "h1" || handles[1] != "h2" {
+		t.Errorf("dial handles = %v, want [h1 h2]", handles)
+	}
+	if len(reconnects) != 2 || !reconnects[0] || !reconnects[1] {
+		t.Errorf("reconnect events = %v", reconnects)
+	}
+	select {
+	case <-second.done:
+	default:
+		t.Error("connection replaced after go-away was not closed")
+	}
+	if err := sess.SendText("still there?"); err != nil || len(third.realtime) != 1 {
+		t.Errorf("send after reconnect went to the wrong connection: %v", err)
+	}
+}
+
+func TestLiveSession_OnReconnectCanSend(t *testing.T) {
+	first := newFakeLiveConn()
+	first.recvErr <- errors.New("connection reset")
+	second := newFakeLiveConn()
+	sess := &LiveSession{session: first}
+	sess.dial = func(context.Context, string) (liveConn, error) { return second, nil }
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sess.Run(context.Background(), LiveHandlers{
+			OnReconnect: func(bool, error) {
+				_ = sess.SendText("resuming")
+				_ = sess.Close()
+			},
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sending from OnReconnect deadlocked")
+	}
+	if len(second.realtime) != 1 {
+		t.Errorf("got %d sends on the new connection, want 1", len(second.realtime))
+	}
+}
+
+func TestLiveSession_ReconnectFailureEndsRun(t *testing.T) {
+	conn := newFakeLiveConn()
+	conn.recvErr <- errors.New("connection reset")
+	sess := &LiveSession{session: conn}
+	dialErr := errors.New("handshake failed")
+	sess.dial = func(context.Context, string) (liveConn, error) { return nil, dialErr }
+
+	if err := sess.Run(context.Background(), LiveHandlers{}); !errors.Is(err, dialErr) {
+		t.Errorf("Run = %v, want %v", err, dialErr)
+	}
+}
+
+func TestWithTranscription(t *testing.T) {
+	base := &genai.LiveConnectConfig{ResponseModalities: []genai.Modality{genai.ModalityAudio}}
+	cfg := WithTranscription(base, true, false)
+	if cfg.InputAudioTranscription == nil || cfg.OutputAudioTranscription != nil {
+		t.Errorf("unexpected transcription config: %+v", cfg)
+	}
+	if base.InputAudioTranscription != nil {
+		t.Error("WithTranscription modified the input config")
+	}
+}
+
+func TestTranscriptAggregator(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(100, 0)}
+	var completed []TranscriptTurn
+	agg := NewTranscriptAggregator(func(turn TranscriptTurn) { completed = append(completed, turn) }).WithClock(clock)
+
+	fragment := func(kind LiveEventKind, text string) {
+		clock.now = clock.now.Add(time.Second)
+		agg.Handle(LiveEvent{Kind: kind, Transcription: &genai.Transcription{Text: text}})
+	}
+
+	fragment(LiveEventInputTranscription, "What time")
+	fragment(LiveEventInputTranscription, " does it open?")
+	fragment(LiveEventOutputTranscription, "It opens")
+	fragment(LiveEventOutputTranscription, " at nine.")
+	agg.Handle(LiveEvent{Kind: LiveEventTurnComplete})
+	fragment(LiveEventInputTranscription, "And on")
+	fragment(LiveEventInputTranscription, " Sundays?")
+	fragment(LiveEventOutputTranscription, "On Sundays it")
+	agg.Handle(LiveEvent{Kind: LiveEventInterrupted})
+	agg.Handle(LiveEvent{Kind: LiveEventTurnComplete})
+
+	want := []TranscriptTurn{
+		{Role: genai.RoleUser, Text: "What time does it open?", Start: time.Unix(101, 0), End: time.Unix(102, 0)},
+		{Role: genai.RoleModel, Text: "It opens at nine.", Start: time.Unix(103, 0), End: time.Unix(104, 0)},
+		{Role: genai.RoleUser, Text: "And on Sundays?", Start: time.Unix(105, 0), End: time.Unix(106, 0)},
+		{Role: genai.RoleModel, Text: "On Sundays it", Start: time.Unix(107, 0), End: time.Unix(107, 0), Interrupted: true},
+	}
+	turns := agg.Turns()
+	if len(turns) != len(want) || len(completed) != len(want) {
+		t.Fatalf("got %d turns (%d callbacks), want %d: %+v", len(turns), len(completed), len(want), turns)
+	}
+	for i := range want {
+		if turns[i] != want[i] {
+			t.Errorf("turn %d = %+v, want %+v", i, turns[i], want[i])
+		}
+	}
+	if got := agg.String(); got != "user: What time does it open?\nmodel: It opens at nine.\nuser: And on Sundays?\nmodel: On Sundays it\n" {
+		t.Errorf("String() = %q", got)
+	}
+}
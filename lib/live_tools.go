@@ -0,0 +1,222 @@
+package genai_sdk
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// liveToolStopTimeout bounds how long Run waits for cancelled tool handlers.
+const liveToolStopTimeout = 5 * time.Second
+
+// LiveToolHandler executes a function call from a live session. The returned
+// map becomes the FunctionResponse payload; an error is reported to the model
+// as {"error": message}. ctx is cancelled if the server cancels the call or
+// the receive loop stops.
+type LiveToolHandler func(ctx context.Context, args map[string]any) (map[string]any, error)
+
+// LiveTools maps function declarations to the Go handlers that answer them.
+type LiveTools struct {
+	mu       sync.RWMutex
+	decls    []*genai.FunctionDeclaration
+	handlers map[string]LiveToolHandler
+	logger   *slog.Logger
+}
+
+// NewLiveTools creates an empty tool set.
+func NewLiveTools() *LiveTools {
+	return &LiveTools{handlers: make(map[string]LiveToolHandler), logger: slog.Default()}
+}
+
+// WithLogger sets the logger used for tool call failures.
+func (t *LiveTools) WithLogger(logger *slog.Logger) *LiveTools {
+	if logger != nil {
+		t.logger = logger
+	}
+	return t
+}
+
+// Register declares a function and the handler that executes it.
+func (t *LiveTools) Register(decl *genai.FunctionDeclaration, handler LiveToolHandler) error {
+	if decl == nil || decl.Name == "" {
+		return fmt.Errorf("function declaration with a name is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("handler for %q is nil", decl.Name)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.handlers[decl.Name]; ok {
+		return fmt.Errorf("function %q is already registered", decl.Name)
+	}
+	t.decls = append(t.decls, decl)
+	t.handlers[decl.Name] = handler
+	return nil
+}
+
+// ConnectConfig returns a copy of cfg with the registered functions declared
+// as an additional tool.
+func (t *LiveTools) ConnectConfig(cfg *genai.LiveConnectConfig) *genai.LiveConnectConfig {
+	out := &genai.LiveConnectConfig{}
+	if cfg != nil {
+		*out = *cfg
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if len(t.decls) > 0 {
+		out.Tools = append(append([]*genai.Tool(nil), out.Tools...), &genai.Tool{
+			FunctionDeclarations: append([]*genai.FunctionDeclaration(nil), t.decls...),
+		})
+	}
+	return out
+}
+
+func (t *LiveTools) handler(name string) (LiveToolHandler, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	h, ok := t.handlers[name]
+	return h, ok
+}
+
+// call runs the handler for fc and builds the response sent to the model.
+func (t *LiveTools) call(ctx context.Context, fc *genai.FunctionCall) *genai.FunctionResponse {
+	resp := &genai.FunctionResponse{ID: fc.ID, Name: fc.Name}
+	h, ok := t.handler(fc.Name)
+	if !ok {
+		resp.Response = map[string]any{"error": fmt.Sprintf("unknown function %q", fc.Name)}
+		return resp
+	}
+	result, err := h(ctx, fc.Args)
+	switch {
+	case err != nil:
+		t.logger.WarnContext(ctx, "live tool call failed",
+			slog.String("function", fc.Name),
+			slog.String("id", fc.ID),
+			slog.Any("error", err))
+		resp.Response = map[string]any{"error": err.Error()}
+	case result == nil:
+		resp.Response = map[string]any{}
+	default:
+		resp.Response = result
+	}
+	return resp
+}
+
+// StartLiveSessionWithTools connects with tools declared in the setup message
+// and answers the model's tool calls while the session's receive loop runs.
+func (ai *GeminiChatClient) StartLiveSessionWithTools(ctx context.Context, model string, cfg *genai.LiveConnectConfig, tools *LiveTools) (*LiveSession, error) {
+	if tools == nil {
+		return nil, fmt.Errorf("tools are required")
+	}
+	sess, err := ai.StartLiveSession(ctx, model, tools.ConnectConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return sess.WithTools(tools), nil
+}
+
+// WithTools makes the receive loop execute tool calls with tools. The tools
+// must also be declared on connect, see LiveTools.ConnectConfig.
+func (s *LiveSession) WithTools(tools *LiveTools) *LiveSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools = tools
+	return s
+}
+
+// startToolCalls runs each function call in its own goroutine and sends its
+// response unless the server cancelled it in the meantime. Responses and
+// cancellations are matched by ID, so calls with an empty ID or one that is
+// already pending are skipped.
+func (s *LiveSession) startToolCalls(ctx context.Context, call *genai.LiveServerToolCall) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tools == nil || call == nil {
+		return
+	}
+	if s.toolCalls == nil {
+		s.toolCalls = make(map[string]context.CancelFunc)
+	}
+	tools := s.tools
+	for _, fc := range call.FunctionCalls {
+		if fc == nil {
+			continue
+		}
+		if _, pending := s.toolCalls[fc.ID]; fc.ID == "" || pending {
+			tools.logger.WarnContext(ctx, "skipping live tool call without a unique id",
+				slog.String("function", fc.Name),
+				slog.String("id", fc.ID))
+			continue
+		}
+		callCtx, cancel := context.WithCancel(ctx)
+		s.toolCalls[fc.ID] = cancel
+		s.toolCallsWG.Add(1)
+		go func() {
+			defer s.toolCallsWG.Done()
+			defer cancel()
+			resp := tools.call(callCtx, fc)
+			if !s.finishToolCall(fc.ID) {
+				return
+			}
+			if err := s.SendToolResponse(resp); err != nil {
+				tools.logger.WarnContext(ctx, "failed to send live tool response",
+					slog.String("function", fc.Name),
+					slog.String("id", fc.ID),
+					slog.Any("error", err))
+			}
+		}()
+	}
+}
+
+// finishToolCall removes id from the pending set and reports whether the
+// call was still wanted.
+func (s *LiveSession) finishToolCall(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.toolCalls[id]
+	delete(s.toolCalls, id)
+	return ok
+}
+
+func (s *LiveSession) cancelToolCalls(ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		if cancel, ok := s.toolCalls[id]; ok {
+			cancel()
+			delete(s.toolCalls, id)
+		}
+	}
+}
+
+// stopToolCalls cancels pending calls and waits up to toolStopTimeout for
+// their handlers to return. Handlers that ignore cancellation are left
+// running; their responses are dropped.
+func (s *LiveSession) stopToolCalls() {
+	s.mu.Lock()
+	for id, cancel := range s.toolCalls {
+		cancel()
+		delete(s.toolCalls, id)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.toolCallsWG.Wait()
+		close(done)
+	}()
+	wait := cmp.Or(s.toolStopTimeout, liveToolStopTimeout)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		s.log().Warn("live tool handlers still running after cancellation",
+			slog.Duration("waited", wait))
+	}
+}
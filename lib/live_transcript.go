@@ -0,0 +1,172 @@
+package genai_sdk
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// WithTranscription returns a copy of cfg with transcription of the user's
+// audio input and/or the model's audio output enabled.
+func WithTranscription(cfg *genai.LiveConnectConfig, input, output bool) *genai.LiveConnectConfig {
+	out := &genai.LiveConnectConfig{}
+	if cfg != nil {
+		*out = *cfg
+	}
+	if input {
+		out.InputAudioTranscription = &genai.AudioTranscriptionConfig{}
+	}
+	if output {
+		out.OutputAudioTranscription = &genai.AudioTranscriptionConfig{}
+	}
+	return out
+}
+
+// TranscriptTurn is one speaker's transcribed turn.
+type TranscriptTurn struct {
+	// Role is genai.RoleUser or genai.RoleModel.
+	Role string
+	Text string
+	// Start and End are when the first and last fragments arrived.
+	Start time.Time
+	End   time.Time
+	// Interrupted is set on model turns cut off by the user barging in.
+	Interrupted bool
+	// LanguageCode is the detected language, when the server reports it.
+	LanguageCode string
+}
+
+// TranscriptAggregator assembles streamed transcription fragments into
+// per-turn transcripts. Feed it every event, e.g. LiveHandlers{OnEvent:
+// agg.Handle}. A user turn ends when the server marks it finished or the model
+// starts answering; a model turn ends on turn complete or interruption.
+type TranscriptAggregator struct {
+	mu     sync.Mutex
+	clock  Clock
+	onTurn func(TranscriptTurn)
+	user   *transcriptBuilder
+	model  *transcriptBuilder
+	turns  []TranscriptTurn
+}
+
+type transcriptBuilder struct {
+	text     strings.Builder
+	start    time.Time
+	end      time.Time
+	language string
+}
+
+// NewTranscriptAggregator creates an aggregator. onTurn, if non-nil, is called
+// with each completed turn.
+func NewTranscriptAggregator(onTurn func(TranscriptTurn)) *TranscriptAggregator {
+	return &TranscriptAggregator{clock: realClock{}, onTurn: onTurn}
+}
+
+// WithClock sets the clock used for timestamps.
+func (a *TranscriptAggregator) WithClock(clock Clock) *TranscriptAggregator {
+	if clock != nil {
+		a.clock = clock
+	}
+	return a
+}
+
+// Handle consumes a live event. Events without transcripts or turn
+// boundaries are ignored.
+func (a *TranscriptAggregator) Handle(ev LiveEvent) {
+	a.mu.Lock()
+	var done []TranscriptTurn
+	switch ev.Kind {
+	case LiveEventInputTranscription:
+		a.user = a.appendFragment(a.user, ev.Transcription)
+		if ev.Transcription != nil && ev.Transcription.Finished {
+			done = a.flush(done, genai.RoleUser, false)
+		}
+	case LiveEventOutputTranscription:
+		done = a.flush(done, genai.RoleUser, false)
+		a.model = a.appendFragment(a.model, ev.Transcription)
+		if ev.Transcription != nil && ev.Transcription.Finished {
+			done = a.flush(done, genai.RoleModel, false)
+		}
+	case LiveEventText, LiveEventAudio:
+		done = a.flush(done, genai.RoleUser, false)
+	case LiveEventTurnComplete:
+		done = a.flush(done, genai.RoleUser, false)
+		done = a.flush(done, genai.RoleModel, false)
+	case LiveEventInterrupted:
+		done = a.flush(done, genai.RoleModel, true)
+	}
+	a.mu.Unlock()
+
+	if a.onTurn != nil {
+		for _, turn := range done {
+			a.onTurn(turn)
+		}
+	}
+}
+
+// Flush completes any partially transcribed turns, e.g. when the session ends.
+func (a *TranscriptAggregator) Flush() {
+	a.Handle(LiveEvent{Kind: LiveEventTurnComplete})
+}
+
+// Turns returns the completed turns in order.
+func (a *TranscriptAggregator) Turns() []TranscriptTurn {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]TranscriptTurn(nil), a.turns...)
+}
+
+// String renders the completed turns as "role: text" lines.
+func (a *TranscriptAggregator) String() string {
+	var b strings.Builder
+	for _, turn := range a.Turns() {
+		b.WriteString(turn.Role)
+		b.WriteString(": ")
+		b.WriteString(turn.Text)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func (a *TranscriptAggregator) appendFragment(tb *transcriptBuilder, t *genai.Transcription) *transcriptBuilder {
+	if t == nil || t.Text == "" {
+		return tb
+	}
+	now := a.clock.Now()
+	if tb == nil {
+		tb = &transcriptBuilder{start: now}
+	}
+	// Fragments carry their own leading whitespace.
+	tb.text.WriteString(t.Text)
+	tb.end = now
+	if t.LanguageCode != "" {
+		tb.language = t.LanguageCode
+	}
+	return tb
+}
+
+func (a *TranscriptAggregator) flush(done []TranscriptTurn, role string, interrupted bool) []TranscriptTurn {
+	tb := &a.user
+	if role == genai.RoleModel {
+		tb = &a.model
+	}
+	if *tb == nil {
+		return done
+	}
+	turn := TranscriptTurn{
+		Role:         role,
+		Text:         strings.TrimSpace((*tb).text.String()),
+		Start:        (*tb).start,
+		End:          (*tb).end,
+		Interrupted:  interrupted,
+		LanguageCode: (*tb).language,
+	}
+	*tb = nil
+	if turn.Text == "" {
+		return done
+	}
+	a.turns = append(a.turns, turn)
+	return append(done, turn)
+}
@@ -20,6 +20,75 @@ type RetryPolicy struct {
 	BaseDelay time.Duration
 	// MaxDelay caps the per-attempt backoff delay.
 	MaxDelay time.Duration
+	// AttemptTimeout bounds each individual attempt. An attempt that runs out
+	// of time is reported as ErrTimeout and retried. Zero means no limit.
+	AttemptTimeout time.Duration
+	// ShouldRetry classifies errors, which have already been passed through
+	// ClassifyError; nil means IsRetryable.
+	ShouldRetry func(err error) bool
+	// OnRetry is called before each backoff wait.
+	OnRetry func(ctx context.Context, event RetryEvent)
+	// Budget, when set, caps retries across every call sharing it so that
+	// retries cannot amplify an outage. Share one budget per client.
+	Budget *RetryBudget
+	// Clock and Rand drive backoff waits and jitter; nil uses the real clock
+	// and the global math/rand source. Tests inject fakes to avoid sleeping.
+	Clock Clock
+	Rand  Rand
+}
+
+// RetryEvent describes a retry that is about to happen.
+type RetryEvent struct {
+	Op string
+	// Attempt is the 1-based number of the retry about to be made.
+	Attempt int
+	// Delay is the backoff wait before the retry.
+	Delay time.Duration
+	// Elapsed is the time spent since the first attempt started.
+	Elapsed time.Duration
+	// Err is the error that triggered the retry.
+	Err error
+}
+
+// Clock abstracts time for retry waits.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// Rand abstracts the jitter source. *math/rand.Rand satisfies it.
+type Rand interface {
+	Int63n(n int64) int64
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type globalRand struct{}
+
+func (globalRand) Int63n(n int64) int64 { return rand.Int63n(n) }
+
+func (p RetryPolicy) clock() Clock {
+	if p.Clock != nil {
+		return p.Clock
+	}
+	return realClock{}
+}
+
+func (p RetryPolicy) rand() Rand {
+	if p.Rand != nil {
+		return p.Rand
+	}
+	return globalRand{}
+}
+
+func (p RetryPolicy) shouldRetry(err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(err)
+	}
+	return IsRetryable(err)
 }
 
 // DefaultRetryPolicy is a sane default for chat/content generation calls.
@@ -29,28 +98,28 @@ var DefaultRetryPolicy = RetryPolicy{
 	MaxDelay:   8 * time.Second,
 }
 
-// retryableStatusCodes are HTTP status codes worth retrying with backoff.
-var retryableStatusCodes = map[int]bool{
-	429: true,
-	500: true,
-	502: true,
-	503: true,
-	504: true,
-}
-
 // IsRetryable reports whether an error from the genai client is transient and
-// worth retrying. Context cancellation/deadline are intentionally NOT retried.
+// worth retrying: rate limits, server errors and timeouts. Exhausted daily
+// quotas and context cancellation/deadline are intentionally NOT retried.
 func IsRetryable(err error) bool {
 	if err == nil {
 		return false
 	}
+	// Checked before the context errors so that an expired per-attempt
+	// timeout, which retryWithBackoff wraps as ErrTimeout, stays retryable.
+	var classified *Error
+	if errors.As(err, &classified) {
+		return isRetryableKind(classified.Kind)
+	}
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return false
 	}
-
+	if errors.As(ClassifyError(err), &classified) {
+		return isRetryableKind(classified.Kind)
+	}
 	var apiErr genai.APIError
 	if errors.As(err, &apiErr) {
-		return retryableStatusCodes[apiErr.Code]
+		return false
 	}
 
 	msg := strings.ToLower(err.Error())
@@ -73,32 +142,69 @@ func IsRetryable(err error) bool {
 	return false
 }
 
+func isRetryableKind(kind error) bool {
+	switch kind {
+	case ErrRateLimited, ErrServerError, ErrTimeout:
+		return true
+	}
+	return false
+}
+
 func backoffDelay(policy RetryPolicy, attempt int) time.Duration {
 	delay := policy.BaseDelay << attempt
 	if delay <= 0 || delay > policy.MaxDelay {
 		delay = policy.MaxDelay
 	}
 	half := delay / 2
-	return half + time.Duration(rand.Int63n(int64(half)+1))
+	return half + time.Duration(policy.rand().Int63n(int64(half)+1))
 }
 
+// retryWithBackoff runs fn until it succeeds, fails permanently, or the policy
+// gives up. fn receives a context bounded by policy.AttemptTimeout; callers
+// whose result outlives the call (such as lazy streams) should ignore it and
+// use ctx instead.
 func retryWithBackoff[T any](
 	ctx context.Context,
 	policy RetryPolicy,
 	logger *slog.Logger,
 	op string,
-	fn func() (T, error),
+	fn func(ctx context.Context) (T, error),
 ) (T, error) {
 	var result T
 	var err error
+	clock := policy.clock()
+	start := clock.Now()
 
 	for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
-		result, err = fn()
-		if err == nil || !IsRetryable(err) || attempt == policy.MaxRetries {
+		result, err = runAttempt(ctx, policy.AttemptTimeout, fn)
+		if err == nil {
+			policy.Budget.onSuccess()
+			return result, nil
+		}
+		err = ClassifyError(err)
+		if !policy.shouldRetry(err) {
+			return result, err
+		}
+		policy.Budget.onFailure()
+		if attempt == policy.MaxRetries {
+			return result, err
+		}
+		if !policy.Budget.allowRetry() {
+			if logger != nil {
+				logger.WarnContext(ctx, "retry budget exhausted; not retrying",
+					slog.String("op", op),
+					slog.String("error", err.Error()),
+				)
+			}
 			return result, err
 		}
 
+		// Prefer the server's RetryInfo hint when it asks for a longer wait
+		// than our own backoff would.
 		delay := backoffDelay(policy, attempt)
+		if retryAfter := RetryAfter(err); retryAfter > delay {
+			delay = retryAfter
+		}
 		if logger != nil {
 			logger.WarnContext(ctx, "retrying LLM call after transient error",
 				slog.String("op", op),
@@ -108,14 +214,34 @@ func retryWithBackoff[T any](
 				slog.String("error", err.Error()),
 			)
 		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(ctx, RetryEvent{
+				Op:      op,
+				Attempt: attempt + 1,
+				Delay:   delay,
+				Elapsed: clock.Now().Sub(start),
+				Err:     err,
+			})
+		}
 
-		timer := time.NewTimer(delay)
 		select {
 		case <-ctx.Done():
-			timer.Stop()
 			return result, ctx.Err()
-		case <-timer.C:
+		case <-clock.After(delay):
 		}
 	}
 	return result, err
+}
+
+func runAttempt[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := fn(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		err = &Error{Kind: ErrTimeout, Err: err}
+	}
+	return result, err
 }
\ No newline at end of file
@@ -0,0 +1,70 @@
+package genai_sdk
+
+import "sync"
+
+// RetryBudget throttles retries across all calls that share it, following the
+// gRPC retry-throttling scheme: every retryable failure spends one token,
+// every success earns TokenRatio tokens, and retries are only allowed while
+// more than half of MaxTokens remain. During an outage the bucket drains and
+// callers fail fast instead of multiplying load on the backend.
+//
+// A nil *RetryBudget allows every retry.
+type RetryBudget struct {
+	mu         sync.Mutex
+	maxTokens  float64
+	tokenRatio float64
+	tokens     float64
+}
+
+// NewRetryBudget creates a full budget. maxTokens defaults to 100 and
+// tokenRatio to 0.1 when non-positive.
+func NewRetryBudget(maxTokens, tokenRatio float64) *RetryBudget {
+	if maxTokens <= 0 {
+		maxTokens = 100
+	}
+	if tokenRatio <= 0 {
+		tokenRatio = 0.1
+	}
+	return &RetryBudget{
+		maxTokens:  maxTokens,
+		tokenRatio: tokenRatio,
+		tokens:     maxTokens,
+	}
+}
+
+// Tokens returns the current token count, for metrics.
+func (b *RetryBudget) Tokens() float64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+func (b *RetryBudget) allowRetry() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
+
+func (b *RetryBudget) onSuccess() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.maxTokens, b.tokens+b.tokenRatio)
+}
+
+func (b *RetryBudget) onFailure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = max(0, b.tokens-1)
+}
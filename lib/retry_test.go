@@ -44,7 +44,7 @@ var fastPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDela
 func TestRetryWithBackoff_SuccessAfterRetry(t *testing.T) {
 	calls := 0
 	got, err := retryWithBackoff(context.Background(), fastPolicy, nil, "test",
-		func() (string, error) {
+		func(context.Context) (string, error) {
 			calls++
 			if calls < 3 {
 				return "", genai.APIError{Code: 503}
@@ -65,7 +65,7 @@ func TestRetryWithBackoff_SuccessAfterRetry(t *testing.T) {
 func TestRetryWithBackoff_ExhaustsRetries(t *testing.T) {
 	calls := 0
 	_, err := retryWithBackoff(context.Background(), fastPolicy, nil, "test",
-		func() (string, error) {
+		func(context.Context) (string, error) {
 			calls++
 			return "", genai.APIError{Code: 503}
 		})
@@ -81,7 +81,7 @@ func TestRetryWithBackoff_ExhaustsRetries(t *testing.T) {
 func TestRetryWithBackoff_NonRetryablePassthrough(t *testing.T) {
 	calls := 0
 	_, err := retryWithBackoff(context.Background(), fastPolicy, nil, "test",
-		func() (string, error) {
+		func(context.Context) (string, error) {
 			calls++
 			return "", genai.APIError{Code: 400}
 		})
@@ -98,7 +98,7 @@ func TestRetryWithBackoff_ContextCancelDuringBackoff(t *testing.T) {
 	calls := 0
 	// Cancel after the first failure so the backoff wait aborts.
 	_, err := retryWithBackoff(ctx, RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: time.Second}, nil, "test",
-		func() (string, error) {
+		func(context.Context) (string, error) {
 			calls++
 			cancel()
 			return "", genai.APIError{Code: 503}
@@ -114,7 +114,7 @@ func TestRetryWithBackoff_ContextCancelDuringBackoff(t *testing.T) {
 func TestRetryWithBackoff_ImmediateSuccess(t *testing.T) {
 	calls := 0
 	got, err := retryWithBackoff(context.Background(), fastPolicy, nil, "test",
-		func() (int, error) {
+		func(context.Context) (int, error) {
 			calls++
 			return 42, nil
 		})
@@ -122,3 +122,115 @@ func TestRetryWithBackoff_ImmediateSuccess(t *testing.T) {
 		t.Errorf("got=%d err=%v calls=%d; want 42, nil, 1", got, err, calls)
 	}
 }
+
+// fakeClock records requested waits and fires immediately.
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// maxRand always picks the top of the jitter range.
+type maxRand struct{}
+
+func (maxRand) Int63n(n int64) int64 { return n - 1 }
+
+func TestRetryWithBackoff_DeterministicClock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 3 * time.Second, Clock: clock, Rand: maxRand{}}
+
+	var events []RetryEvent
+	policy.OnRetry = func(_ context.Context, ev RetryEvent) { events = append(events, ev) }
+
+	_, err := retryWithBackoff(context.Background(), policy, nil, "test",
+		func(context.Context) (string, error) {
+			return "", genai.APIError{Code: 503}
+		})
+	if !errors.Is(err, ErrServerError) {
+		t.Fatalf("expected ErrServerError, got %v", err)
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	if len(clock.waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", clock.waits, want)
+	}
+	for i := range want {
+		if clock.waits[i] != want[i] {
+			t.Errorf("wait[%d] = %v, want %v", i, clock.waits[i], want[i])
+		}
+	}
+	if len(events) != 3 || events[2].Attempt != 3 || events[2].Elapsed != 3*time.Second {
+		t.Errorf("unexpected OnRetry events: %+v", events)
+	}
+}
+
+func TestRetryWithBackoff_CustomShouldRetry(t *testing.T) {
+	policy := fastPolicy
+	policy.ShouldRetry = func(err error) bool { return errors.Is(err, ErrInvalidArgument) }
+
+	calls := 0
+	_, _ = retryWithBackoff(context.Background(), policy, nil, "test",
+		func(context.Context) (string, error) {
+			calls++
+			return "", genai.APIError{Code: 400}
+		})
+	if calls != 4 {
+		t.Errorf("custom classifier should retry 400s; got %d calls", calls)
+	}
+}
+
+func TestRetryWithBackoff_AttemptTimeout(t *testing.T) {
+	policy := fastPolicy
+	policy.AttemptTimeout = 10 * time.Millisecond
+
+	calls := 0
+	got, err := retryWithBackoff(context.Background(), policy, nil, "test",
+		func(ctx context.Context) (string, error) {
+			calls++
+			if calls == 1 {
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			return "ok", nil
+		})
+	if err != nil || got != "ok" {
+		t.Fatalf("got %q, %v; want ok after timed-out attempt", got, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRetryBudget_StopsRetriesWhenDrained(t *testing.T) {
+	policy := fastPolicy
+	policy.Budget = NewRetryBudget(4, 1)
+
+	calls := 0
+	_, err := retryWithBackoff(context.Background(), policy, nil, "test",
+		func(context.Context) (string, error) {
+			calls++
+			return "", genai.APIError{Code: 503}
+		})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	// 4 tokens, retries allowed while tokens > 2: fail(3) retry, fail(2) stop.
+	if calls != 2 {
+		t.Errorf("expected budget to stop after 2 calls, got %d", calls)
+	}
+
+	_, _ = retryWithBackoff(context.Background(), policy, nil, "test",
+		func(context.Context) (string, error) { return "ok", nil })
+	if got := policy.Budget.Tokens(); got != 3 {
+		t.Errorf("tokens after success = %v, want 3", got)
+	}
+}
@@ -0,0 +1,156 @@
+package genai_sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// SpeechModel is the default text-to-speech model.
+const SpeechModel = "gemini-2.5-flash-preview-tts"
+
+// speechFormat is the format Gemini TTS models return unless the response
+// MIME type says otherwise: 24 kHz, 16-bit, mono.
+var speechFormat = PCMFormat{SampleRate: 24000, Channels: 1, BitsPerSample: 16}
+
+// SpeechRequest describes text to narrate. Set Voice for a single speaker or
+// Speakers for a dialogue; in the latter case Text must label each line with
+// the speaker names, e.g. "Guide: Welcome!\nVisitor: Thanks!".
+type SpeechRequest struct {
+	Text string
+	// Model defaults to SpeechModel.
+	Model string
+	// Voice is a prebuilt voice name such as "Kore" or "Puck".
+	Voice    string
+	Speakers []SpeakerVoice
+	// LanguageCode optionally pins the output language, e.g. "pt-PT".
+	LanguageCode string
+}
+
+// SpeakerVoice assigns a prebuilt voice to a named speaker.
+type SpeakerVoice struct {
+	Speaker string
+	Voice   string
+}
+
+// SpeechAudio is raw PCM returned by a TTS model.
+type SpeechAudio struct {
+	PCM    []byte
+	Format PCMFormat
+}
+
+// WAV returns the audio wrapped in a WAV container.
+func (a *SpeechAudio) WAV() ([]byte, error) {
+	return EncodeWAV(a.PCM, a.Format)
+}
+
+// WriteWAV writes the audio to w as a WAV file.
+func (a *SpeechAudio) WriteWAV(w io.Writer) error {
+	return WriteWAV(w, a.PCM, a.Format)
+}
+
+// GenerateSpeech narrates req.Text with a Gemini audio-response model.
+func (g *GeminiChatClient) GenerateSpeech(ctx context.Context, req SpeechRequest) (*SpeechAudio, error) {
+	if strings.TrimSpace(req.Text) == "" {
+		return nil, fmt.Errorf("text cannot be empty")
+	}
+	model := req.Model
+	if model == "" {
+		model = SpeechModel
+	}
+	speech, err := speechConfig(req)
+	if err != nil {
+		return nil, err
+	}
+	config := &genai.GenerateContentConfig{
+		ResponseModalities: []string{string(genai.ModalityAudio)},
+		SpeechConfig:       speech,
+	}
+
+	resp, err := retryWithBackoff(ctx, g.retryPolicy, g.logger, "GenerateSpeech",
+		func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+			return withBulkhead(ctx, g.bulkhead, PriorityInteractive, func() (*genai.GenerateContentResponse, error) {
+				return g.client.Models.GenerateContent(ctx, model, genai.Text(req.Text), config)
+			})
+		})
+	if err != nil {
+		return nil, err
+	}
+	return extractSpeech(resp)
+}
+
+func speechConfig(req SpeechRequest) (*genai.SpeechConfig, error) {
+	cfg := &genai.SpeechConfig{LanguageCode: req.LanguageCode}
+	switch {
+	case len(req.Speakers) > 0 && req.Voice != "":
+		return nil, fmt.Errorf("set either Voice or Speakers, not both")
+	case len(req.Speakers) > 0:
+		multi := &genai.MultiSpeakerVoiceConfig{}
+		for _, s := range req.Speakers {
+			if s.Speaker == "" || s.Voice == "" {
+				return nil, fmt.Errorf("speaker and voice are required for every speaker")
+			}
+			multi.SpeakerVoiceConfigs = append(multi.SpeakerVoiceConfigs, &genai.SpeakerVoiceConfig{
+				Speaker:     s.Speaker,
+				VoiceConfig: prebuiltVoice(s.Voice),
+			})
+		}
+		cfg.MultiSpeakerVoiceConfig = multi
+	case req.Voice != "":
+		cfg.VoiceConfig = prebuiltVoice(req.Voice)
+	}
+	return cfg, nil
+}
+
+func prebuiltVoice(name string) *genai.VoiceConfig {
+	return &genai.VoiceConfig{PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{VoiceName: name}}
+}
+
+// extractSpeech concatenates the audio parts of resp. TTS models report the
+// format in the MIME type, e.g. "audio/L16;codec=pcm;rate=24000".
+func extractSpeech(resp *genai.GenerateContentResponse) (*SpeechAudio, error) {
+	if resp == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+	audio := &SpeechAudio{Format: speechFormat}
+	for _, cand := range resp.Candidates {
+		if cand == nil || cand.Content == nil {
+			continue
+		}
+		for _, part := range cand.Content.Parts {
+			if part == nil || part.InlineData == nil || !strings.HasPrefix(part.InlineData.MIMEType, "audio/") {
+				continue
+			}
+			if len(audio.PCM) == 0 {
+				audio.Format = pcmFormatFromMIME(part.InlineData.MIMEType, speechFormat)
+			}
+			audio.PCM = append(audio.PCM, part.InlineData.Data...)
+		}
+		if len(audio.PCM) > 0 {
+			return audio, nil
+		}
+	}
+	return nil, fmt.Errorf("no audio content in response")
+}
+
+// pcmFormatFromMIME reads the rate and channels parameters of a raw PCM MIME
+// type, keeping fallback values for anything not specified.
+func pcmFormatFromMIME(mimeType string, fallback PCMFormat) PCMFormat {
+	format := fallback
+	_, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return format
+	}
+	if rate, err := strconv.Atoi(params["rate"]); err == nil && rate > 0 {
+		format.SampleRate = rate
+	}
+	if channels, err := strconv.Atoi(params["channels"]); err == nil && channels > 0 {
+		format.Channels = channels
+	}
+	return format
+}
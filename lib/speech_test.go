@@ -0,0 +1,88 @@
+package genai_sdk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestWriteWAV_Header(t *testing.T) {
+	pcm := make([]byte, 480) // 10ms of 24kHz 16-bit mono
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, pcm, PCMFormat{SampleRate: 24000, Channels: 1, BitsPerSample: 16}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wav := buf.Bytes()
+	if len(wav) != 44+len(pcm) {
+		t.Fatalf("len = %d, want %d", len(wav), 44+len(pcm))
+	}
+	if string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" || string(wav[36:40]) != "data" {
+		t.Errorf("bad chunk ids: %q", wav[:40])
+	}
+	le := binary.LittleEndian
+	if got := le.Uint32(wav[4:8]); got != uint32(36+len(pcm)) {
+		t.Errorf("RIFF size = %d", got)
+	}
+	if got := le.Uint16(wav[22:24]); got != 1 {
+		t.Errorf("channels = %d, want 1", got)
+	}
+	if got := le.Uint32(wav[24:28]); got != 24000 {
+		t.Errorf("sample rate = %d, want 24000", got)
+	}
+	if got := le.Uint32(wav[28:32]); got != 48000 {
+		t.Errorf("byte rate = %d, want 48000", got)
+	}
+	if got := le.Uint32(wav[40:44]); got != uint32(len(pcm)) {
+		t.Errorf("data size = %d", got)
+	}
+}
+
+func TestWriteWAV_RejectsPartialFrames(t *testing.T) {
+	err := WriteWAV(&bytes.Buffer{}, []byte{1, 2, 3}, PCMFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16})
+	if err == nil {
+		t.Error("expected error for odd-length 16-bit PCM")
+	}
+}
+
+func TestExtractSpeech(t *testing.T) {
+	resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		Content: &genai.Content{Parts: []*genai.Part{
+			{InlineData: &genai.Blob{MIMEType: "audio/L16;codec=pcm;rate=22050", Data: []byte{1, 2}}},
+			{InlineData: &genai.Blob{MIMEType: "audio/L16;codec=pcm;rate=22050", Data: []byte{3, 4}}},
+		}},
+	}}}
+	audio, err := extractSpeech(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(audio.PCM, []byte{1, 2, 3, 4}) {
+		t.Errorf("PCM = %v", audio.PCM)
+	}
+	if audio.Format.SampleRate != 22050 || audio.Format.Channels != 1 || audio.Format.BitsPerSample != 16 {
+		t.Errorf("format = %+v", audio.Format)
+	}
+	if _, err := extractSpeech(&genai.GenerateContentResponse{}); err == nil {
+		t.Error("expected error for response without audio")
+	}
+}
+
+func TestSpeechConfig(t *testing.T) {
+	single, err := speechConfig(SpeechRequest{Voice: "Kore"})
+	if err != nil || single.VoiceConfig.PrebuiltVoiceConfig.VoiceName != "Kore" {
+		t.Errorf("single speaker config = %+v, %v", single, err)
+	}
+
+	multi, err := speechConfig(SpeechRequest{Speakers: []SpeakerVoice{
+		{Speaker: "Guide", Voice: "Kore"},
+		{Speaker: "Visitor", Voice: "Puck"},
+	}})
+	if err != nil || len(multi.MultiSpeakerVoiceConfig.SpeakerVoiceConfigs) != 2 {
+		t.Errorf("multi speaker config = %+v, %v", multi, err)
+	}
+
+	if _, err := speechConfig(SpeechRequest{Voice: "Kore", Speakers: []SpeakerVoice{{Speaker: "A", Voice: "B"}}}); err == nil {
+		t.Error("expected error when both Voice and Speakers are set")
+	}
+}
@@ -0,0 +1,105 @@
+package genai_sdk
+
+import (
+	"iter"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+const (
+	// ThinkingBudgetDynamic lets the model decide how much to think.
+	ThinkingBudgetDynamic int32 = -1
+	// ThinkingBudgetOff disables thinking on models that allow it.
+	ThinkingBudgetOff int32 = 0
+)
+
+// WithThinking returns a copy of cfg (which may be nil) with the given
+// thinking budget in tokens. includeThoughts asks the model to return thought
+// summaries, which ExtractThoughts and SplitThoughtStream separate from the
+// answer.
+func WithThinking(cfg *genai.GenerateContentConfig, budget int32, includeThoughts bool) *genai.GenerateContentConfig {
+	var out genai.GenerateContentConfig
+	if cfg != nil {
+		out = *cfg
+	}
+	out.ThinkingConfig = &genai.ThinkingConfig{
+		ThinkingBudget:  genai.Ptr(budget),
+		IncludeThoughts: includeThoughts,
+	}
+	return &out
+}
+
+// ExtractThoughts returns the thought summaries of the first candidate that
+// has any. It returns "" when the response carries no thoughts, which is the
+// case unless IncludeThoughts was requested.
+func ExtractThoughts(resp *genai.GenerateContentResponse) string {
+	if resp == nil {
+		return ""
+	}
+	for _, cand := range resp.Candidates {
+		if cand == nil {
+			continue
+		}
+		if thoughts, _ := splitParts(cand.Content); thoughts != "" {
+			return thoughts
+		}
+	}
+	return ""
+}
+
+// ExtractThoughtsAndText returns the thought summaries and the answer text of
+// the first candidate with answer text.
+func ExtractThoughtsAndText(resp *genai.GenerateContentResponse) (thoughts, text string, err error) {
+	_, thoughts, text, err = answerCandidate(resp)
+	return thoughts, text, err
+}
+
+// StreamDelta is one chunk of a streamed response, split into thought and
+// answer text. Response is the raw chunk for callers that need more.
+type StreamDelta struct {
+	Thought  string
+	Text     string
+	Response *genai.GenerateContentResponse
+}
+
+// SplitThoughtStream adapts a generate-content stream into thought and
+// answer deltas, so UIs can render the model's reasoning separately.
+func SplitThoughtStream(stream iter.Seq2[*genai.GenerateContentResponse, error]) iter.Seq2[StreamDelta, error] {
+	return func(yield func(StreamDelta, error) bool) {
+		for resp, err := range stream {
+			if err != nil {
+				yield(StreamDelta{}, err)
+				return
+			}
+			if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0] == nil {
+				continue
+			}
+			thought, text := splitParts(resp.Candidates[0].Content)
+			if thought == "" && text == "" {
+				continue
+			}
+			if !yield(StreamDelta{Thought: thought, Text: text, Response: resp}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func splitParts(content *genai.Content) (thoughts, text string) {
+	if content == nil {
+		return "", ""
+	}
+	var t, a strings.Builder
+	for _, part := range content.Parts {
+		if part == nil || part.Text == "" {
+			continue
+		}
+		if part.Thought {
+			t.WriteString(part.Text)
+		} else {
+			a.WriteString(part.Text)
+		}
+	}
+	return t.String(), a.String()
+}
@@ -0,0 +1,101 @@
+package genai_sdk
+
+import (
+	"iter"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func thinkingResponse() *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: &genai.Content{
+				Parts: []*genai.Part{
+					{Text: "Considering nearby landmarks. ", Thought: true},
+					{Text: "Visit the Belém Tower."},
+				},
+			},
+		}},
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     10,
+			CandidatesTokenCount: 6,
+			ThoughtsTokenCount:   42,
+			TotalTokenCount:      58,
+		},
+	}
+}
+
+func TestExtractText_SkipsThoughts(t *testing.T) {
+	got, err := ExtractText(thinkingResponse())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Visit the Belém Tower." {
+		t.Errorf("got %q, want answer text only", got)
+	}
+}
+
+func TestExtractThoughts(t *testing.T) {
+	if got := ExtractThoughts(thinkingResponse()); got != "Considering nearby landmarks. " {
+		t.Errorf("got %q", got)
+	}
+	if got := ExtractThoughts(nil); got != "" {
+		t.Errorf("nil response should have no thoughts, got %q", got)
+	}
+	withNil := thinkingResponse()
+	withNil.Candidates = append([]*genai.Candidate{nil}, withNil.Candidates...)
+	if got := ExtractThoughts(withNil); got != "Considering nearby landmarks. " {
+		t.Errorf("nil candidate should be skipped, got %q", got)
+	}
+}
+
+func TestExtractUsageDetails(t *testing.T) {
+	u := ExtractUsageDetails(thinkingResponse())
+	if u.Prompt != 10 || u.Candidates != 6 || u.Thoughts != 42 || u.Total != 58 {
+		t.Errorf("unexpected usage: %+v", u)
+	}
+}
+
+func TestWithThinking(t *testing.T) {
+	base := &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.5)}
+	cfg := WithThinking(base, 1024, true)
+	if cfg.ThinkingConfig == nil || *cfg.ThinkingConfig.ThinkingBudget != 1024 || !cfg.ThinkingConfig.IncludeThoughts {
+		t.Errorf("unexpected thinking config: %+v", cfg.ThinkingConfig)
+	}
+	if *cfg.Temperature != 0.5 {
+		t.Error("other fields should be preserved")
+	}
+	if base.ThinkingConfig != nil {
+		t.Error("WithThinking must not mutate its input")
+	}
+}
+
+func TestSplitThoughtStream(t *testing.T) {
+	chunks := []*genai.GenerateContentResponse{
+		{Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []*genai.Part{{Text: "hmm", Thought: true}}}}}},
+		{Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []*genai.Part{{Text: "Hello"}}}}}},
+		{},
+	}
+	var stream iter.Seq2[*genai.GenerateContentResponse, error] = func(yield func(*genai.GenerateContentResponse, error) bool) {
+		for _, c := range chunks {
+			if !yield(c, nil) {
+				return
+			}
+		}
+	}
+
+	var deltas []StreamDelta
+	for d, err := range SplitThoughtStream(stream) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		deltas = append(deltas, d)
+	}
+	if len(deltas) != 2 {
+		t.Fatalf("got %d deltas, want 2", len(deltas))
+	}
+	if deltas[0].Thought != "hmm" || deltas[0].Text != "" || deltas[1].Text != "Hello" {
+		t.Errorf("unexpected deltas: %+v", deltas)
+	}
+}
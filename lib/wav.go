@@ -0,0 +1,182 @@
+package genai_sdk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// PCMFormat describes raw little-endian PCM audio.
+type PCMFormat struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+}
+
+func (f PCMFormat) validate() error {
+	if f.SampleRate <= 0 || f.Channels <= 0 {
+		return fmt.Errorf("invalid PCM format %+v", f)
+	}
+	if f.BitsPerSample != 8 && f.BitsPerSample != 16 && f.BitsPerSample != 24 && f.BitsPerSample != 32 {
+		return fmt.Errorf("unsupported bits per sample: %d", f.BitsPerSample)
+	}
+	return nil
+}
+
+func (f PCMFormat) blockAlign() int {
+	return f.Channels * f.BitsPerSample / 8
+}
+
+// WriteWAV writes pcm to w wrapped in a canonical 44-byte RIFF/WAVE header.
+func WriteWAV(w io.Writer, pcm []byte, format PCMFormat) error {
+	if err := format.validate(); err != nil {
+		return err
+	}
+	if len(pcm)%format.blockAlign() != 0 {
+		return fmt.Errorf("PCM length %d is not a multiple of the %d-byte frame size", len(pcm), format.blockAlign())
+	}
+
+	header := struct {
+		ChunkID       [4]byte
+		ChunkSize     uint32
+		Format        [4]byte
+		Subchunk1ID   [4]byte
+		Subchunk1Size uint32
+		AudioFormat   uint16
+		NumChannels   uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Subchunk2ID   [4]byte
+		Subchunk2Size uint32
+	}{
+		ChunkID:       [4]byte{'R', 'I', 'F', 'F'},
+		ChunkSize:     uint32(36 + len(pcm)),
+		Format:        [4]byte{'W', 'A', 'V', 'E'},
+		Subchunk1ID:   [4]byte{'f', 'm', 't', ' '},
+		Subchunk1Size: 16,
+		AudioFormat:   1, // linear PCM
+		NumChannels:   uint16(format.Channels),
+		SampleRate:    uint32(format.SampleRate),
+		ByteRate:      uint32(format.SampleRate * format.blockAlign()),
+		BlockAlign:    uint16(format.blockAlign()),
+		BitsPerSample: uint16(format.BitsPerSample),
+		Subchunk2ID:   [4]byte{'d', 'a', 't', 'a'},
+		Subchunk2Size: uint32(len(pcm)),
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	_, err := w.Write(pcm)
+	return err
+}
+
+const (
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xFFFE
+
+	// maxWAVFmtChunk bounds the fmt chunk; real ones are 16, 18 or 40 bytes.
+	maxWAVFmtChunk = 1024
+)
+
+// ReadWAV reads a linear PCM WAV stream and returns its sample data and
+// format. Chunks other than "fmt " and "data" are skipped.
+func ReadWAV(r io.Reader) ([]byte, PCMFormat, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, PCMFormat{}, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, PCMFormat{}, fmt.Errorf("not a RIFF/WAVE stream")
+	}
+
+	var format PCMFormat
+	haveFormat := false
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, PCMFormat{}, fmt.Errorf("failed to read WAV chunk: %w", err)
+		}
+		id, size := string(hdr[0:4]), binary.LittleEndian.Uint32(hdr[4:8])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, PCMFormat{}, fmt.Errorf("WAV fmt chunk too short: %d bytes", size)
+			}
+			if size > maxWAVFmtChunk {
+				return nil, PCMFormat{}, fmt.Errorf("WAV fmt chunk too long: %d bytes", size)
+			}
+			buf := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return nil, PCMFormat{}, fmt.Errorf("failed to read WAV fmt chunk: %w", err)
+			}
+			var err error
+			if format, err = parseWAVFormat(buf[:size]); err != nil {
+				return nil, PCMFormat{}, err
+			}
+			haveFormat = true
+
+		case "data":
+			if !haveFormat {
+				return nil, PCMFormat{}, fmt.Errorf("WAV data chunk before fmt chunk")
+			}
+			var pcm []byte
+			var err error
+			if size == 0 || size == 0xFFFFFFFF {
+				// Streaming writers leave the size unset; read to EOF.
+				pcm, err = io.ReadAll(r)
+			} else {
+				// Read through a limit rather than trusting the header's size
+				// for the allocation, so a bogus size can't exhaust memory.
+				pcm, err = io.ReadAll(io.LimitReader(r, int64(size)))
+				if err == nil && len(pcm) < int(size) {
+					err = io.ErrUnexpectedEOF
+				}
+			}
+			if err != nil {
+				return nil, PCMFormat{}, fmt.Errorf("failed to read WAV data: %w", err)
+			}
+			// Drop a trailing partial frame rather than fail on truncated files.
+			pcm = pcm[:len(pcm)-len(pcm)%format.blockAlign()]
+			return pcm, format, nil
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size)+int64(size%2)); err != nil {
+				return nil, PCMFormat{}, fmt.Errorf("failed to skip WAV %q chunk: %w", id, err)
+			}
+		}
+	}
+}
+
+func parseWAVFormat(b []byte) (PCMFormat, error) {
+	le := binary.LittleEndian
+	audioFormat := le.Uint16(b[0:2])
+	if audioFormat == wavFormatExtensible && len(b) >= 26 {
+		// The sub-format GUID starts with the real format code.
+		audioFormat = le.Uint16(b[24:26])
+	}
+	if audioFormat != wavFormatPCM {
+		return PCMFormat{}, fmt.Errorf("unsupported WAV encoding %#x: only linear PCM is supported", audioFormat)
+	}
+	format := PCMFormat{
+		Channels:      int(le.Uint16(b[2:4])),
+		SampleRate:    int(le.Uint32(b[4:8])),
+		BitsPerSample: int(le.Uint16(b[14:16])),
+	}
+	if err := format.validate(); err != nil {
+		return PCMFormat{}, err
+	}
+	return format, nil
+}
+
+// EncodeWAV is WriteWAV into a new byte slice.
+func EncodeWAV(pcm []byte, format PCMFormat) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, pcm, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}